@@ -1,57 +1,165 @@
+// Command migration manages the versioned migrations of the database in the migrations directory.
+//
+// Diff writes the migration from the committed migrations to the ent schema, which is reviewed and committed:
+//
+//	go run ./cmd/migration diff add_user_phone
+//
+// Up applies the pending migrations, down reverts the last applied ones and status lists them.
+// The checksums of applied migrations are validated, a modified or missing migration makes the command fail.
 package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 
 	"entgo.io/ent/dialect"
 	_ "github.com/lib/pq"
 	"gitlab.com/trustify/core/config"
-	"gitlab.com/trustify/core/ent"
-	"gitlab.com/trustify/core/ent/migrate"
+	"gitlab.com/trustify/core/pkg/infrastructure/datastore"
 )
 
+const usage = `usage: migration [-dir migrations] <command> [arguments]
+
+commands:
+  diff <name>  write the migration from the migrations to the ent schema
+  up [n]       apply the pending migrations, at most n
+  down [n]     revert the last n applied migrations, 1 by default
+  status       list the migrations and validate the applied ones`
+
 func main() {
+	log.SetFlags(0)
+	dir := flag.String("dir", datastore.MigrationDir, "directory of the migrations")
+	flag.Usage = func() { log.Print(usage) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		log.Fatal(usage)
+	}
+
 	config.ReadConfig(config.ReadConfigOption{})
 
-	client, err := newClient()
+	db, err := sql.Open(dialect.Postgres, datastore.New())
 	if err != nil {
 		log.Fatalf("error opening postgres connection: %v", err)
 	}
-	defer client.Close()
+	defer db.Close()
+	m := datastore.NewMigrator(db, *dir)
+
+	ctx := context.Background()
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "diff":
+		os.Exit(diff(ctx, m, args))
+	case "up":
+		os.Exit(up(ctx, m, args))
+	case "down":
+		os.Exit(down(ctx, m, args))
+	case "status":
+		os.Exit(status(ctx, m))
+	default:
+		log.Fatalf("unknown command %q\n%s", flag.Arg(0), usage)
+	}
+}
+
+// diff runs the diff command and returns the exit status
+func diff(ctx context.Context, m *datastore.Migrator, args []string) int {
+	if len(args) != 1 {
+		log.Fatal(usage)
+	}
+
+	files, err := m.Diff(ctx, args[0])
+	if err != nil {
+		log.Printf("diff failed: %v", err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Println("no changes")
+		return 0
+	}
+	for _, f := range files {
+		fmt.Printf("created %s\n", f)
+	}
+
+	return 0
+}
+
+// up runs the up command and returns the exit status
+func up(ctx context.Context, m *datastore.Migrator, args []string) int {
+	applied, err := m.Up(ctx, count(args, 0))
+	for _, mig := range applied {
+		fmt.Printf("applied %s\n", mig.File())
+	}
+	if err != nil {
+		log.Printf("up failed: %v", err)
+		return 1
+	}
+	if len(applied) == 0 {
+		fmt.Println("no pending migrations")
+	}
 
-	createDBSchema(client)
+	return 0
 }
 
-func newDSN() string {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.C.Database.Host,
-		config.C.Database.Port,
-		config.C.Database.User,
-		config.C.Database.Password,
-		config.C.Database.Name,
-		config.C.Database.SSL,
-	)
-	return dsn
+// down runs the down command and returns the exit status
+func down(ctx context.Context, m *datastore.Migrator, args []string) int {
+	reverted, err := m.Down(ctx, count(args, 1))
+	for _, mig := range reverted {
+		fmt.Printf("reverted %s\n", mig.File())
+	}
+	if err != nil {
+		log.Printf("down failed: %v", err)
+		return 1
+	}
+	if len(reverted) == 0 {
+		fmt.Println("no applied migrations")
+	}
+
+	return 0
 }
 
-func newClient() (*ent.Client, error) {
-	var entOpt []ent.Option
-	entOpt = append(entOpt, ent.Debug())
+// status runs the status command and returns the exit status, which is not zero if an applied migration is invalid
+func status(ctx context.Context, m *datastore.Migrator) int {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		log.Printf("status failed: %v", err)
+		return 1
+	}
 
-	dsn := newDSN()
+	for _, s := range statuses {
+		var state string
+		switch {
+		case s.Pending():
+			state = "pending"
+		case s.Missing():
+			state = "missing"
+		case s.Modified():
+			state = "modified"
+		default:
+			state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s_%s\t%s\n", s.Version, s.Name, state)
+	}
+	if err := datastore.ValidateMigrations(statuses); err != nil {
+		log.Printf("invalid migrations: %v", err)
+		return 1
+	}
 
-	return ent.Open(dialect.Postgres, dsn, entOpt...)
+	return 0
 }
 
-func createDBSchema(client *ent.Client) {
-	if err := client.Schema.Create(
-		context.Background(),
-		migrate.WithDropIndex(true),
-		migrate.WithDropColumn(true),
-	); err != nil {
-		log.Fatalf("error creating database resources: %v", err)
+// count parses the optional count argument of up and down
+func count(args []string, def int) int {
+	if len(args) == 0 {
+		return def
 	}
+	n, err := strconv.Atoi(args[0])
+	if len(args) > 1 || err != nil || n < 1 {
+		log.Fatal(usage)
+	}
+
+	return n
 }
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Change is a difference between two schemas
+type Change struct {
+	// Path of the changed element, e.g. User, User.email or Query.users(first:)
+	Path string
+	// Message describes the change
+	Message string
+	// Breaking changes may break existing clients
+	Breaking bool
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Path, c.Message)
+}
+
+// Diff returns the changes from the old to the new schema ordered by path
+func Diff(old, new *ast.Schema) []Change {
+	d := &differ{}
+
+	for name, o := range old.Types {
+		if o.BuiltIn {
+			continue
+		}
+		n, ok := new.Types[name]
+		if !ok {
+			d.breaking(name, "%s was removed", kindName(o.Kind))
+			continue
+		}
+		d.definition(o, n)
+	}
+	for name, n := range new.Types {
+		if _, ok := old.Types[name]; !ok && !n.BuiltIn {
+			d.safe(name, "%s was added", kindName(n.Kind))
+		}
+	}
+
+	sort.Slice(d.changes, func(i, j int) bool {
+		if d.changes[i].Path != d.changes[j].Path {
+			return d.changes[i].Path < d.changes[j].Path
+		}
+		return d.changes[i].Message < d.changes[j].Message
+	})
+
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) breaking(path string, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{Path: path, Message: fmt.Sprintf(format, args...), Breaking: true})
+}
+
+func (d *differ) safe(path string, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) definition(o, n *ast.Definition) {
+	if o.Kind != n.Kind {
+		d.breaking(o.Name, "changed from %s to %s", kindName(o.Kind), kindName(n.Kind))
+		return
+	}
+
+	switch o.Kind {
+	case ast.Object, ast.Interface:
+		d.outputFields(o, n)
+		d.members(o.Name, "interface", o.Interfaces, n.Interfaces)
+	case ast.InputObject:
+		d.inputFields(o, n)
+	case ast.Union:
+		d.members(o.Name, "member", o.Types, n.Types)
+	case ast.Enum:
+		d.enumValues(o, n)
+	}
+}
+
+func (d *differ) outputFields(o, n *ast.Definition) {
+	for _, of := range o.Fields {
+		path := o.Name + "." + of.Name
+		nf := n.Fields.ForName(of.Name)
+		if nf == nil {
+			d.breaking(path, "field was removed")
+			continue
+		}
+		d.outputType(path, of.Type, nf.Type)
+		d.arguments(path, of.Arguments, nf.Arguments)
+	}
+	for _, nf := range n.Fields {
+		if o.Fields.ForName(nf.Name) == nil {
+			d.safe(o.Name+"."+nf.Name, "field was added")
+		}
+	}
+}
+
+func (d *differ) inputFields(o, n *ast.Definition) {
+	for _, of := range o.Fields {
+		path := o.Name + "." + of.Name
+		nf := n.Fields.ForName(of.Name)
+		if nf == nil {
+			d.breaking(path, "input field was removed")
+			continue
+		}
+		d.inputType(path, of.Type, nf.Type)
+	}
+	for _, nf := range n.Fields {
+		if o.Fields.ForName(nf.Name) != nil {
+			continue
+		}
+		path := o.Name + "." + nf.Name
+		if isRequired(nf.Type, nf.DefaultValue) {
+			d.breaking(path, "required input field was added")
+		} else {
+			d.safe(path, "optional input field was added")
+		}
+	}
+}
+
+func (d *differ) arguments(field string, o, n ast.ArgumentDefinitionList) {
+	for _, oa := range o {
+		path := fmt.Sprintf("%s(%s:)", field, oa.Name)
+		na := n.ForName(oa.Name)
+		if na == nil {
+			d.breaking(path, "argument was removed")
+			continue
+		}
+		d.inputType(path, oa.Type, na.Type)
+	}
+	for _, na := range n {
+		if o.ForName(na.Name) != nil {
+			continue
+		}
+		path := fmt.Sprintf("%s(%s:)", field, na.Name)
+		if isRequired(na.Type, na.DefaultValue) {
+			d.breaking(path, "required argument was added")
+		} else {
+			d.safe(path, "optional argument was added")
+		}
+	}
+}
+
+func (d *differ) enumValues(o, n *ast.Definition) {
+	for _, ov := range o.EnumValues {
+		if n.EnumValues.ForName(ov.Name) == nil {
+			d.breaking(o.Name+"."+ov.Name, "enum value was removed")
+		}
+	}
+	for _, nv := range n.EnumValues {
+		if o.EnumValues.ForName(nv.Name) == nil {
+			d.safe(o.Name+"."+nv.Name, "enum value was added")
+		}
+	}
+}
+
+func (d *differ) members(path string, kind string, o, n []string) {
+	for _, m := range o {
+		if !contains(n, m) {
+			d.breaking(path, "%s %s was removed", kind, m)
+		}
+	}
+	for _, m := range n {
+		if !contains(o, m) {
+			d.safe(path, "%s %s was added", kind, m)
+		}
+	}
+}
+
+// outputType compares the type of a field returned to clients.
+// Clients can handle a field becoming non-null, but not a field becoming nullable.
+// The elements of lists are compared as well, also if the nullability of the list changed.
+func (d *differ) outputType(path string, o, n *ast.Type) {
+	if !sameNamedType(o, n) {
+		d.breaking(path, "type changed from %s to %s", o, n)
+		return
+	}
+	switch {
+	case o.NonNull && !n.NonNull:
+		d.breaking(path, "type changed from %s to nullable %s", o, n)
+	case !o.NonNull && n.NonNull:
+		d.safe(path, "type changed from %s to non-null %s", o, n)
+	}
+	if o.Elem != nil {
+		d.outputType(path, o.Elem, n.Elem)
+	}
+}
+
+// inputType compares the type of a value sent by clients.
+// Clients can handle an input becoming nullable, but not an input becoming non-null.
+// The elements of lists are compared as well, also if the nullability of the list changed.
+func (d *differ) inputType(path string, o, n *ast.Type) {
+	if !sameNamedType(o, n) {
+		d.breaking(path, "type changed from %s to %s", o, n)
+		return
+	}
+	switch {
+	case !o.NonNull && n.NonNull:
+		d.breaking(path, "type changed from %s to non-null %s", o, n)
+	case o.NonNull && !n.NonNull:
+		d.safe(path, "type changed from %s to nullable %s", o, n)
+	}
+	if o.Elem != nil {
+		d.inputType(path, o.Elem, n.Elem)
+	}
+}
+
+// sameNamedType reports whether both types have the same name and list nesting ignoring nullability
+func sameNamedType(o, n *ast.Type) bool {
+	if (o.Elem == nil) != (n.Elem == nil) {
+		return false
+	}
+	if o.Elem != nil {
+		return sameNamedType(o.Elem, n.Elem)
+	}
+	return o.NamedType == n.NamedType
+}
+
+func isRequired(t *ast.Type, defaultValue *ast.Value) bool {
+	return t.NonNull && defaultValue == nil
+}
+
+func kindName(k ast.DefinitionKind) string {
+	switch k {
+	case ast.InputObject:
+		return "input"
+	case ast.Object:
+		return "type"
+	default:
+		return strings.ToLower(string(k))
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestDiff(t *testing.T) {
+	const base = `
+		type Query { user(id: ID!, active: Boolean): User }
+		type User { id: ID!, name: String, email: String! }
+		input UserInput { name: String, email: String! }
+		enum Role { ADMIN USER }
+	`
+	const lists = `
+		type Query { users(ids: [ID!]!, roles: [Role!]): [User!] }
+		type User { id: ID!, name: String, email: String! }
+		input UserInput { name: String, email: String! }
+		enum Role { ADMIN USER }
+	`
+
+	type args struct {
+		old string
+		new string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []Change
+	}{
+		{
+			name: "it should report nothing if the schema is unchanged",
+			args: args{new: base},
+			want: nil,
+		},
+		{
+			name: "it should report removed fields and enum values as breaking",
+			args: args{new: `
+				type Query { user(id: ID!, active: Boolean): User }
+				type User { id: ID!, email: String! }
+				input UserInput { name: String, email: String! }
+				enum Role { USER }
+			`},
+			want: []Change{
+				{Path: "Role.ADMIN", Message: "enum value was removed", Breaking: true},
+				{Path: "User.name", Message: "field was removed", Breaking: true},
+			},
+		},
+		{
+			name: "it should report nullability changes clients cannot handle as breaking",
+			args: args{new: `
+				type Query { user(id: ID!, active: Boolean!): User }
+				type User { id: ID!, name: String!, email: String }
+				input UserInput { name: String!, email: String }
+				enum Role { ADMIN USER }
+			`},
+			want: []Change{
+				{Path: "Query.user(active:)", Message: "type changed from Boolean to non-null Boolean!", Breaking: true},
+				{Path: "User.email", Message: "type changed from String! to nullable String", Breaking: true},
+				{Path: "User.name", Message: "type changed from String to non-null String!", Breaking: false},
+				{Path: "UserInput.email", Message: "type changed from String! to nullable String", Breaking: false},
+				{Path: "UserInput.name", Message: "type changed from String to non-null String!", Breaking: true},
+			},
+		},
+		{
+			name: "it should report additions as safe unless they are required inputs",
+			args: args{new: `
+				type Query { user(id: ID!, active: Boolean, first: Int!): User, users: [User!]! }
+				type User { id: ID!, name: String, email: String! }
+				input UserInput { name: String, email: String!, age: Int!, nickname: String }
+				enum Role { ADMIN USER GUEST }
+				type Group { id: ID! }
+			`},
+			want: []Change{
+				{Path: "Group", Message: "type was added", Breaking: false},
+				{Path: "Query.user(first:)", Message: "required argument was added", Breaking: true},
+				{Path: "Query.users", Message: "field was added", Breaking: false},
+				{Path: "Role.GUEST", Message: "enum value was added", Breaking: false},
+				{Path: "UserInput.age", Message: "required input field was added", Breaking: true},
+				{Path: "UserInput.nickname", Message: "optional input field was added", Breaking: false},
+			},
+		},
+		{
+			name: "it should compare list elements if the nullability of the list changed",
+			args: args{old: lists, new: `
+				type Query { users(ids: [ID]!, roles: [Role!]!): [User]! }
+				type User { id: ID!, name: String, email: String! }
+				input UserInput { name: String, email: String! }
+				enum Role { ADMIN USER }
+			`},
+			want: []Change{
+				{Path: "Query.users", Message: "type changed from User! to nullable User", Breaking: true},
+				{Path: "Query.users", Message: "type changed from [User!] to non-null [User]!", Breaking: false},
+				{Path: "Query.users(ids:)", Message: "type changed from ID! to nullable ID", Breaking: false},
+				{Path: "Query.users(roles:)", Message: "type changed from [Role!] to non-null [Role!]!", Breaking: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.args.old == "" {
+				tt.args.old = base
+			}
+			old, err := loadSchema(&ast.Source{Name: "old", Input: tt.args.old})
+			if err != nil {
+				t.Fatal(err)
+			}
+			new, err := loadSchema(&ast.Source{Name: "new", Input: tt.args.new})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := Diff(old, new)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	got := allowed([]*ast.Source{{Input: `
+		type User {
+			# schemacheck:allow User.age
+			id: ID!
+		}
+	`}})
+
+	assert.Equal(t, map[string]bool{"User.age": true}, got)
+}
@@ -0,0 +1,86 @@
+// Command schemacheck compares the GraphQL schema with the committed snapshot.
+//
+// Breaking changes, like removed fields, nullability changes clients cannot handle or removed enum values,
+// make the command exit with a non-zero status. A breaking change is accepted if one of the schema files
+// contains an annotation with the path of the change:
+//
+//	# schemacheck:allow User.age
+//
+// Run with -update to write the current schema to the snapshot once the changes are released,
+// the annotations can be removed afterwards.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func main() {
+	schemaGlob := flag.String("schema", "graph/*.graphqls", "glob of the schema files")
+	snapshotPath := flag.String("snapshot", "graph/schema.snapshot.graphql", "path of the schema snapshot")
+	printSDL := flag.Bool("print", false, "print the merged schema and exit")
+	update := flag.Bool("update", false, "write the merged schema to the snapshot and exit")
+	flag.Parse()
+
+	sources, err := readSources(*schemaGlob)
+	if err != nil {
+		log.Fatalf("could not read schema: %v", err)
+	}
+	current, err := loadSchema(sources...)
+	if err != nil {
+		log.Fatalf("could not load schema: %v", err)
+	}
+	sdl := printSchema(current)
+
+	switch {
+	case *printSDL:
+		fmt.Print(sdl)
+		return
+	case *update:
+		if err := ioutil.WriteFile(*snapshotPath, []byte(sdl), 0644); err != nil {
+			log.Fatalf("could not write snapshot: %v", err)
+		}
+		return
+	}
+
+	b, err := ioutil.ReadFile(*snapshotPath)
+	if err != nil {
+		log.Fatalf("could not read snapshot: %v", err)
+	}
+	snapshot, err := loadSchema(&ast.Source{Name: *snapshotPath, Input: string(b)})
+	if err != nil {
+		log.Fatalf("could not load snapshot: %v", err)
+	}
+
+	if !report(Diff(snapshot, current), allowed(sources)) {
+		os.Exit(1)
+	}
+}
+
+// report prints the changes and returns false if there are breaking changes which are not allowed
+func report(changes []Change, allowed map[string]bool) bool {
+	if len(changes) == 0 {
+		fmt.Println("no changes")
+		return true
+	}
+
+	ok := true
+	for _, c := range changes {
+		switch {
+		case !c.Breaking:
+			fmt.Printf("safe:     %s\n", c)
+		case allowed[c.Path]:
+			fmt.Printf("allowed:  %s\n", c)
+		default:
+			fmt.Printf("breaking: %s\n", c)
+			ok = false
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/99designs/gqlgen/plugin"
+	"github.com/99designs/gqlgen/plugin/federation"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// allowPattern matches the annotation of an intentional breaking change, e.g.
+//
+//	# schemacheck:allow User.age
+var allowPattern = regexp.MustCompile(`#\s*schemacheck:allow\s+(\S+)`)
+
+// readSources reads every schema file matching the glob
+func readSources(glob string) ([]*ast.Source, error) {
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no schema files match %s", glob)
+	}
+	sort.Strings(files)
+
+	sources := make([]*ast.Source, len(files))
+	for i, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		sources[i] = &ast.Source{Name: f, Input: string(b)}
+	}
+
+	return sources, nil
+}
+
+// loadSchema loads the sources together with the directives gqlgen injects for federation
+func loadSchema(sources ...*ast.Source) (*ast.Schema, error) {
+	fed := federation.New().(plugin.EarlySourceInjector).InjectSourceEarly()
+	schema, err := gqlparser.LoadSchema(append([]*ast.Source{fed}, sources...)...)
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// printSchema returns the SDL of the schema without built in definitions
+func printSchema(schema *ast.Schema) string {
+	var buf bytes.Buffer
+	formatter.NewFormatter(&buf).FormatSchema(schema)
+	return buf.String()
+}
+
+// allowed returns the paths of the breaking changes annotated as intentional in the sources
+func allowed(sources []*ast.Source) map[string]bool {
+	result := map[string]bool{}
+	for _, s := range sources {
+		for _, m := range allowPattern.FindAllStringSubmatch(s.Input, -1) {
+			result[m[1]] = true
+		}
+	}
+	return result
+}
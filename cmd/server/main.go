@@ -1,28 +1,59 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
+	"net/http"
 
 	_ "github.com/lib/pq"
 	"gitlab.com/trustify/core/config"
 	"gitlab.com/trustify/core/ent"
 	"gitlab.com/trustify/core/pkg/adapter/controller"
+	"gitlab.com/trustify/core/pkg/infrastructure/cache"
 	"gitlab.com/trustify/core/pkg/infrastructure/datastore"
 	"gitlab.com/trustify/core/pkg/infrastructure/graphql"
+	"gitlab.com/trustify/core/pkg/infrastructure/idempotency"
 	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/pkg/infrastructure/storage"
+	"gitlab.com/trustify/core/pkg/infrastructure/tracing"
 	"gitlab.com/trustify/core/pkg/registry"
+	"gitlab.com/trustify/core/pkg/usercase/repository"
+	"gitlab.com/trustify/core/pkg/util/logger"
 )
 
 func main() {
 	config.ReadConfig(config.ReadConfigOption{})
+	logger.Init()
+
+	shutdownTracing := newTracing()
 
 	client := newDBClient()
-	ctrl := newController(client)
+	fileStorage := newStorage()
+	ctrl := newController(client, fileStorage)
+	responseCache := newResponseCache(client)
+	go idempotency.NewStore(client).PurgeEvery(context.Background(), config.C.Idempotency.PurgeInterval)
 
 	srv := graphql.NewServer(client, ctrl)
-	e := router.New(srv)
+	files, _ := fileStorage.(http.Handler)
+	e := router.New(srv, responseCache, files, ctrl)
 
-	e.Logger.Fatal(e.Start(":" + config.C.HttpServer.Port))
+	err := e.Start(":" + config.C.HttpServer.Port)
+	shutdownTracing()
+	e.Logger.Fatal(err)
+}
+
+func newTracing() func() {
+	shutdown, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatalf("failed initializing tracing: %v", err)
+	}
+
+	return func() {
+		if err := shutdown(context.Background()); err != nil {
+			slog.Error("failed flushing spans", slog.Any("error", err))
+		}
+	}
 }
 
 func newDBClient() *ent.Client {
@@ -34,7 +65,29 @@ func newDBClient() *ent.Client {
 	return client
 }
 
-func newController(client *ent.Client) controller.Controller {
-	r := registry.New(client)
+func newStorage() repository.Storage {
+	s, err := storage.New()
+	if err != nil {
+		log.Fatalf("failed creating file storage: %v", err)
+	}
+
+	return s
+}
+
+// newResponseCache returns the configured response cache which is invalidated by mutations of the client
+func newResponseCache(client *ent.Client) cache.Cache {
+	c, err := cache.New()
+	if err != nil {
+		log.Fatalf("failed creating response cache: %v", err)
+	}
+	if c != nil {
+		client.Use(cache.InvalidationHook(c))
+	}
+
+	return c
+}
+
+func newController(client *ent.Client, fileStorage repository.Storage) controller.Controller {
+	r := registry.New(client, fileStorage)
 	return r.NewController()
 }
@@ -0,0 +1,98 @@
+// Command users manages users from the command line.
+//
+// Import creates users from a CSV file with the same rules as the importUsers mutation:
+//
+//	go run ./cmd/users import [-dry-run] users.csv
+//
+// Errors are printed per row and make the command exit with a non-zero status.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/infrastructure/datastore"
+	"gitlab.com/trustify/core/pkg/registry"
+)
+
+const usage = `usage: users <command> [arguments]
+
+commands:
+  import [-dry-run] <file.csv>  create users from a CSV file with the columns firstName, lastName, email and password`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	switch os.Args[1] {
+	case "import":
+		os.Exit(importUsers(os.Args[2:]))
+	default:
+		log.Fatalf("unknown command %q\n%s", os.Args[1], usage)
+	}
+}
+
+// importUsers runs the import command and returns the exit status
+func importUsers(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only validate the file")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal(usage)
+	}
+
+	config.ReadConfig(config.ReadConfigOption{})
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("could not open file: %v", err)
+	}
+	defer f.Close()
+
+	client, err := datastore.NewClient()
+	if err != nil {
+		log.Fatalf("failed opening postgres client: %v", err)
+	}
+	defer client.Close()
+
+	ctrl := registry.New(client, nil).NewController()
+
+	report, err := ctrl.User.Import(context.Background(), f, *dryRun)
+	if err != nil {
+		log.Printf("import failed: %v", err)
+		return 1
+	}
+	printReport(report)
+	if len(report.Errors) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func printReport(report *model.ImportUsersReport) {
+	for _, e := range report.Errors {
+		if e.Field != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %s: %s\n", e.Row, *e.Field, e.Message)
+		} else {
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Row, e.Message)
+		}
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+		fmt.Printf("%d rows, %d errors, no users imported\n", report.Rows, len(report.Errors))
+	case report.DryRun:
+		fmt.Printf("%d rows, no errors, dry run\n", report.Rows)
+	default:
+		fmt.Printf("%d rows, %d users imported\n", report.Rows, report.Imported)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/spf13/viper"
 	"gitlab.com/trustify/core/pkg/util/environment"
@@ -20,10 +21,102 @@ type config struct {
 		Password string
 		Name     string
 		SSL      string
+		// SlowQueryThreshold is the duration after which a statement is logged as slow
+		SlowQueryThreshold time.Duration
+		// NPlusOneThreshold is the number of identical statements in one request which are reported as likely N+1
+		NPlusOneThreshold int
 	}
 	HttpServer struct {
 		Port string
 	}
+	GraphQL struct {
+		MaxNodes int
+		// MaxBatchSize limits the number of operations sent in one request
+		MaxBatchSize int
+		// MaxUploadSize limits the size of multipart requests uploading files in bytes
+		MaxUploadSize int64
+		// MaxBulkSize limits the number of items created or updated by one bulk mutation
+		MaxBulkSize int
+	}
+	Search struct {
+		// SimilarityThreshold is the minimum word similarity (0-1) of users returned by the trigram fallback of searches
+		SimilarityThreshold float64
+		// MaxPageSize limits the number of users returned by one search
+		MaxPageSize int
+	}
+	Cache struct {
+		// Store of cached responses of anonymous GET queries, one of memory or none
+		Store string
+		// MaxEntries limits the number of cached responses, 0 means unlimited
+		MaxEntries int
+	}
+	Storage struct {
+		// Driver of the file storage, one of local or s3
+		Driver string
+		// SignedURLExpiry is the validity of signed URLs of stored files
+		SignedURLExpiry time.Duration
+		Local           struct {
+			// Dir is the directory the files are stored in
+			Dir string
+			// BaseURL is prepended to signed URLs, e.g. http://localhost:8080. Relative URLs are returned if empty
+			BaseURL string
+			// Secret signs the URLs of stored files
+			Secret string
+		}
+		S3 struct {
+			// Endpoint of the S3-compatible API, e.g. https://s3.eu-central-1.amazonaws.com
+			Endpoint        string
+			Region          string
+			Bucket          string
+			AccessKeyID     string
+			SecretAccessKey string
+			// PathStyle addresses the bucket in the path instead of the host, required by most S3-compatible servers
+			PathStyle bool
+		}
+	}
+	Export struct {
+		// Tokens are the bearer tokens allowed to export users, exports are disabled if empty
+		Tokens []string
+		// BatchSize is the number of users loaded per statement while streaming an export
+		BatchSize int
+	}
+	Import struct {
+		// MaxRows limits the number of rows of imported files
+		MaxRows int
+		// BatchSize is the number of users created per statement
+		BatchSize int
+	}
+	Idempotency struct {
+		// Expiry is the duration for which the response of a mutation is replayed for its Idempotency-Key
+		Expiry time.Duration
+		// PurgeInterval is the interval of deleting expired keys
+		PurgeInterval time.Duration
+	}
+	Avatar struct {
+		// MaxSize limits the size of uploaded avatars in bytes
+		MaxSize int64
+		// ThumbnailSize is the width and height of avatar thumbnails in pixels
+		ThumbnailSize int
+	}
+	Metrics struct {
+		// Operations are the operation names used as labels of operation metrics, other names are counted as other
+		Operations []string
+	}
+	Log struct {
+		// Level of the logger, one of debug, info, warn or error
+		Level string
+		// SQL enables logging of SQL statements, bound arguments are redacted
+		SQL bool
+	}
+	Tracing struct {
+		// Exporter of spans, one of otlp, stdout or none
+		Exporter string
+		// Endpoint of the OTLP collector, e.g. localhost:4317
+		Endpoint string
+		// Insecure disables TLS for the OTLP collector
+		Insecure    bool
+		ServiceName string
+	}
 }
 
 var C config
@@ -24,7 +24,8 @@ type config struct {
 
 // hooks per client, for fast access.
 type hooks struct {
-	User []ent.Hook
+	IdempotencyKey []ent.Hook
+	User           []ent.Hook
 }
 
 // Options applies the options on the config object.
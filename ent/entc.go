@@ -4,17 +4,29 @@
 package main
 
 import (
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 
 	"entgo.io/contrib/entgql"
 	"entgo.io/ent/entc"
 	"entgo.io/ent/entc/gen"
+	"github.com/99designs/gqlgen/plugin"
+	"github.com/99designs/gqlgen/plugin/federation"
+	"gopkg.in/yaml.v2"
 )
 
 func main() {
+	configPath, cleanup, err := federationConfig("../gqlgen.yml")
+	if err != nil {
+		log.Fatalf("Error: failed creating gqlgen config: %v", err)
+	}
+	defer cleanup()
+
 	ex, err := entgql.NewExtension(
 		entgql.WithWhereFilters(true),
-		entgql.WithConfigPath("../gqlgen.yml"),
+		entgql.WithConfigPath(configPath),
 		entgql.WithSchemaPath("../graph/ent.graphqls"),
 	)
 	if err != nil {
@@ -23,10 +35,54 @@ func main() {
 
 	opts := []entc.Option{
 		entc.Extensions(ex),
-		entc.TemplateDir("./template"),
+		// sql/modifier allows raw SQL in queries, e.g. the full-text search of users
+		entc.FeatureNames("sql/modifier"),
 	}
 
-	if err := entc.Generate("./schema", &gen.Config{}, opts...); err != nil {
+	// the templates may use the functions of entgql, e.g. filterNodes to leave out types skipped by GraphQL
+	templates := gen.MustParse(gen.NewTemplate("template").Funcs(entgql.TemplateFuncs).ParseDir("./template"))
+	if err := entc.Generate("./schema", &gen.Config{Templates: []*gen.Template{templates}}, opts...); err != nil {
 		log.Fatalf("Error: failed running ent codegen: %v", err)
 	}
 }
+
+// federationConfig writes a copy of the gqlgen config which also loads the directives of the federation plugin.
+// gqlgen injects them while generating, but entgql loads the schema without plugins.
+func federationConfig(path string) (string, func(), error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	var cfg yaml.MapSlice
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return "", nil, err
+	}
+
+	dir := filepath.Dir(path)
+	schemaPath := filepath.Join(dir, ".entc.federation.graphqls")
+	configPath := filepath.Join(dir, ".entc.gqlgen.yml")
+	cleanup := func() {
+		os.Remove(schemaPath)
+		os.Remove(configPath)
+	}
+
+	source := federation.New().(plugin.EarlySourceInjector).InjectSourceEarly()
+	if err := ioutil.WriteFile(schemaPath, []byte(source.Input), 0644); err != nil {
+		return "", cleanup, err
+	}
+	for i, item := range cfg {
+		if item.Key == "schema" {
+			schema, _ := item.Value.([]interface{})
+			cfg[i].Value = append(schema, filepath.Base(schemaPath))
+		}
+	}
+	b, err = yaml.Marshal(cfg)
+	if err != nil {
+		return "", cleanup, err
+	}
+	if err := ioutil.WriteFile(configPath, b, 0644); err != nil {
+		return "", cleanup, err
+	}
+
+	return configPath, cleanup, nil
+}
@@ -14,6 +14,7 @@ type CreateUserInput struct {
 	LastName  string
 	Email     string
 	Password  string
+	Avatar    *string
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
 }
@@ -24,6 +25,9 @@ func (i *CreateUserInput) Mutate(m *UserCreate) {
 	m.SetLastName(i.LastName)
 	m.SetEmail(i.Email)
 	m.SetPassword(i.Password)
+	if v := i.Avatar; v != nil {
+		m.SetAvatar(*v)
+	}
 	if v := i.CreatedAt; v != nil {
 		m.SetCreatedAt(*v)
 	}
@@ -40,12 +44,21 @@ func (c *UserCreate) SetInput(i CreateUserInput) *UserCreate {
 
 // UpdateUserInput represents a mutation input for updating users.
 type UpdateUserInput struct {
-	ID        ulid.ID
-	FirstName *string
-	LastName  *string
-	Email     *string
-	Password  *string
-	UpdatedAt *time.Time
+	ID ulid.ID
+	// ExpectedVersion is the version the update is based on, it fails if the user was updated since.
+	ExpectedVersion *int
+	FirstName       *string
+	LastName        *string
+	Email           *string
+	Password        *string
+	Avatar          *string
+	ClearAvatar     bool
+	UpdatedAt       *time.Time
+}
+
+// ClearNulls sets the Clear fields of the optional fields which are null in the raw GraphQL input,
+// omitted fields are left unchanged.
+func (i *UpdateUserInput) ClearNulls(raw map[string]interface{}) {
 }
 
 // Mutate applies the UpdateUserInput on the UserMutation.
@@ -62,6 +75,12 @@ func (i *UpdateUserInput) Mutate(m *UserMutation) {
 	if v := i.Password; v != nil {
 		m.SetPassword(*v)
 	}
+	if i.ClearAvatar {
+		m.ClearAvatar()
+	}
+	if v := i.Avatar; v != nil {
+		m.SetAvatar(*v)
+	}
 	if v := i.UpdatedAt; v != nil {
 		m.SetUpdatedAt(*v)
 	}
@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"gitlab.com/trustify/core/ent/schema/ulid"
+	"gitlab.com/trustify/core/pkg/const/globalid"
+)
+
+// IdempotencyKeyMaxLength limits the length of the keys sent by clients
+const IdempotencyKeyMaxLength = 255
+
+// IdempotencyKey holds the schema definition for the IdempotencyKey entity.
+// It stores the response of a mutation sent with an Idempotency-Key header, so that retries replay it.
+// It is internal and not part of the GraphQL schema.
+type IdempotencyKey struct {
+	ent.Schema
+}
+
+// Annotations of the IdempotencyKey.
+func (IdempotencyKey) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entgql.Skip(),
+	}
+}
+
+// Fields of the IdempotencyKey.
+func (IdempotencyKey) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").
+			GoType(ulid.ID("")).
+			DefaultFunc(func() ulid.ID {
+				return ulid.MustNew(globalid.New().IdempotencyKey.Prefix)
+			}),
+		// key is the Idempotency-Key header sent by the client
+		field.String("key").NotEmpty().MaxLen(IdempotencyKeyMaxLength).Unique().Immutable(),
+		// operation_hash identifies the query and the variables of the mutation the key was used with
+		field.String("operation_hash").NotEmpty().Immutable(),
+		// response is the JSON encoded response, it is empty while the mutation is executed
+		field.Bytes("response").Optional(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("expires_at"),
+	}
+}
+
+// Edges of the IdempotencyKey.
+func (IdempotencyKey) Edges() []ent.Edge {
+	return nil
+}
+
+// Indexes of the IdempotencyKey.
+func (IdempotencyKey) Indexes() []ent.Index {
+	return []ent.Index{
+		// expired keys are purged periodically
+		index.Fields("expires_at"),
+	}
+}
@@ -3,6 +3,7 @@ package schema
 import (
 	"time"
 
+	"entgo.io/contrib/entgql"
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
 	"gitlab.com/trustify/core/ent/schema/ulid"
@@ -14,6 +15,13 @@ type User struct {
 	ent.Schema
 }
 
+// Mixin of the User.
+func (User) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		VersionMixin{},
+	}
+}
+
 // Fields of the User.
 func (User) Fields() []ent.Field {
 	return []ent.Field{
@@ -26,8 +34,13 @@ func (User) Fields() []ent.Field {
 		field.String("last_name").NotEmpty(),
 		field.String("email").NotEmpty().Unique(),
 		field.String("password"),
-		field.Time("created_at").Default(time.Now()).Immutable(),
-		field.Time("updated_at").Default(time.Now()),
+		// avatar is the storage key of the uploaded avatar, its thumbnail is stored next to it
+		field.String("avatar").Optional().Nillable().
+			Annotations(entgql.Skip()),
+		field.Time("created_at").Default(time.Now()).Immutable().
+			Annotations(entgql.Type("DateTime")),
+		field.Time("updated_at").Default(time.Now()).
+			Annotations(entgql.Type("DateTime")),
 	}
 }
 
@@ -0,0 +1,38 @@
+package schema
+
+// Full-text search of users. ent cannot describe generated columns and expression indexes, so the search column
+// and the indexes are created by the statements below, see datastore.MigrateOptions.
+const (
+	// UserSearchColumn is a generated tsvector of the names and the email address of a user.
+	// Names are weighted higher than the parts of the email address.
+	UserSearchColumn = "search"
+	// UserSearchIndex is the GIN index of UserSearchColumn
+	UserSearchIndex = "users_search_idx"
+	// UserSearchConfig is the text search configuration. Names are neither stemmed nor filtered by stop words.
+	UserSearchConfig = "simple"
+	// UserSearchText is the text of a user which is highlighted and compared by the trigram fallback
+	UserSearchText = "first_name || ' ' || last_name || ' ' || email"
+	// UserSearchExtension provides the trigram functions, operators and operator classes
+	UserSearchExtension = "pg_trgm"
+	// UserSearchTrigramIndex is the trigram index of UserSearchText used by the <% operator of the fallback
+	UserSearchTrigramIndex = "users_search_trgm_idx"
+)
+
+// UserSearchStatements create the search column and the indexes, they may be executed repeatedly
+var UserSearchStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS ` + UserSearchExtension,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS ` + UserSearchColumn + ` tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('` + UserSearchConfig + `', first_name), 'A') ||
+		setweight(to_tsvector('` + UserSearchConfig + `', last_name), 'A') ||
+		setweight(to_tsvector('` + UserSearchConfig + `', translate(email, '@.', '  ')), 'B')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS ` + UserSearchIndex + ` ON users USING GIN (` + UserSearchColumn + `)`,
+	`CREATE INDEX IF NOT EXISTS ` + UserSearchTrigramIndex + ` ON users USING GIN ((` + UserSearchText + `) gin_trgm_ops)`,
+}
+
+// UserSearchDropStatements revert UserSearchStatements, the pg_trgm extension is kept
+var UserSearchDropStatements = []string{
+	`DROP INDEX IF EXISTS ` + UserSearchTrigramIndex,
+	`DROP INDEX IF EXISTS ` + UserSearchIndex,
+	`ALTER TABLE users DROP COLUMN IF EXISTS ` + UserSearchColumn,
+}
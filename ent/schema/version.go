@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"context"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+)
+
+// VersionFieldName is the field of VersionMixin.
+// The update inputs of entities with this field accept an expected version instead of the version itself.
+const VersionFieldName = "version"
+
+// VersionMixin adds a version which is incremented by every update of an entity.
+// Updates conditioned on the version read before detect concurrent changes (optimistic locking).
+type VersionMixin struct {
+	mixin.Schema
+}
+
+// Fields of the VersionMixin.
+func (VersionMixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int(VersionFieldName).Default(1),
+	}
+}
+
+// Hooks of the VersionMixin.
+func (VersionMixin) Hooks() []ent.Hook {
+	return []ent.Hook{
+		func(next ent.Mutator) ent.Mutator {
+			return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+				if m.Op().Is(ent.OpUpdate | ent.OpUpdateOne) {
+					if err := m.AddField(VersionFieldName, 1); err != nil {
+						return nil, err
+					}
+				}
+				return next.Mutate(ctx, m)
+			})
+		},
+	}
+}
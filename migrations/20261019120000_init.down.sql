@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS users_search_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS search;
+-- reverse: create index "users_email_key" to table: "users"
+DROP INDEX "users_email_key";
+-- reverse: create "users" table
+DROP TABLE "users";
+-- reverse: create index "idempotencykey_expires_at" to table: "idempotency_keys"
+DROP INDEX "idempotencykey_expires_at";
+-- reverse: create index "idempotency_keys_key_key" to table: "idempotency_keys"
+DROP INDEX "idempotency_keys_key_key";
+-- reverse: create "idempotency_keys" table
+DROP TABLE "idempotency_keys";
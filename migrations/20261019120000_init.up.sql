@@ -0,0 +1,20 @@
+-- Initial schema. It uses IF NOT EXISTS, so that databases created by the former automatic migration are adopted.
+
+-- create "idempotency_keys" table
+CREATE TABLE IF NOT EXISTS "idempotency_keys" ("id" character varying NOT NULL, "key" character varying NOT NULL, "operation_hash" character varying NOT NULL, "response" bytea NULL, "created_at" timestamp with time zone NOT NULL, "expires_at" timestamp with time zone NOT NULL, PRIMARY KEY ("id"));
+-- create index "idempotency_keys_key_key" to table: "idempotency_keys"
+CREATE UNIQUE INDEX IF NOT EXISTS "idempotency_keys_key_key" ON "idempotency_keys" ("key");
+-- create index "idempotencykey_expires_at" to table: "idempotency_keys"
+CREATE INDEX IF NOT EXISTS "idempotencykey_expires_at" ON "idempotency_keys" ("expires_at");
+-- create "users" table
+CREATE TABLE IF NOT EXISTS "users" ("id" character varying NOT NULL, "version" bigint NOT NULL DEFAULT 1, "first_name" character varying NOT NULL, "last_name" character varying NOT NULL, "email" character varying NOT NULL, "password" character varying NOT NULL, "avatar" character varying NULL, "created_at" timestamp with time zone NOT NULL, "updated_at" timestamp with time zone NOT NULL, PRIMARY KEY ("id"));
+-- create index "users_email_key" to table: "users"
+CREATE UNIQUE INDEX IF NOT EXISTS "users_email_key" ON "users" ("email");
+-- full-text search of users, see ent/schema/user_search.go
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', first_name), 'A') ||
+	setweight(to_tsvector('simple', last_name), 'A') ||
+	setweight(to_tsvector('simple', translate(email, '@.', '  ')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS users_search_idx ON users USING GIN (search);
@@ -0,0 +1,2 @@
+-- trigram index of the fuzzy fallback of user searches, see ent/schema/user_search.go
+CREATE INDEX IF NOT EXISTS users_search_trgm_idx ON users USING GIN ((first_name || ' ' || last_name || ' ' || email) gin_trgm_ops);
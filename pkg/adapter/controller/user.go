@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"io"
 
 	"gitlab.com/trustify/core/pkg/entity/model"
 	"gitlab.com/trustify/core/pkg/usercase/usecase"
@@ -17,6 +18,14 @@ type User interface {
 	List(ctx context.Context, after *model.Cursor, first *int, before *model.Cursor, last *int, where *model.UserWhereInput) (*model.UserConnection, error)
 	Create(ctx context.Context, input model.CreateUserInput) (*model.User, error)
 	Update(ctx context.Context, input model.UpdateUserInput) (*model.User, error)
+	UploadAvatar(ctx context.Context, id model.ID, file model.Upload) (*model.User, error)
+	AvatarURL(ctx context.Context, u *model.User, thumbnail bool) (*string, error)
+	Search(ctx context.Context, query string, first *int, after *model.Cursor) (*model.UserSearchConnection, error)
+	Aggregate(ctx context.Context, where *model.UserWhereInput, groupBy []model.UserGroupField, interval *model.AggregateInterval) ([]*model.UserAggregate, error)
+	Export(ctx context.Context, where *model.UserWhereInput, fn func(users []*model.User) error) error
+	Import(ctx context.Context, r io.Reader, dryRun bool) (*model.ImportUsersReport, error)
+	CreateMany(ctx context.Context, inputs []*model.CreateUserInput) ([]*model.User, error)
+	UpdateMany(ctx context.Context, where *model.UserWhereInput, set model.BulkUserPatch) ([]*model.User, error)
 }
 
 // NewUserController returns user controller
@@ -39,3 +48,35 @@ func (u *user) Create(ctx context.Context, input model.CreateUserInput) (*model.
 func (u *user) Update(ctx context.Context, input model.UpdateUserInput) (*model.User, error) {
 	return u.userUsecase.Update(ctx, input)
 }
+
+func (u *user) UploadAvatar(ctx context.Context, id model.ID, file model.Upload) (*model.User, error) {
+	return u.userUsecase.UploadAvatar(ctx, id, file)
+}
+
+func (u *user) AvatarURL(ctx context.Context, usr *model.User, thumbnail bool) (*string, error) {
+	return u.userUsecase.AvatarURL(ctx, usr, thumbnail)
+}
+
+func (u *user) Search(ctx context.Context, query string, first *int, after *model.Cursor) (*model.UserSearchConnection, error) {
+	return u.userUsecase.Search(ctx, query, first, after)
+}
+
+func (u *user) Aggregate(ctx context.Context, where *model.UserWhereInput, groupBy []model.UserGroupField, interval *model.AggregateInterval) ([]*model.UserAggregate, error) {
+	return u.userUsecase.Aggregate(ctx, where, groupBy, interval)
+}
+
+func (u *user) Export(ctx context.Context, where *model.UserWhereInput, fn func(users []*model.User) error) error {
+	return u.userUsecase.Export(ctx, where, fn)
+}
+
+func (u *user) Import(ctx context.Context, r io.Reader, dryRun bool) (*model.ImportUsersReport, error) {
+	return u.userUsecase.Import(ctx, r, dryRun)
+}
+
+func (u *user) CreateMany(ctx context.Context, inputs []*model.CreateUserInput) ([]*model.User, error) {
+	return u.userUsecase.CreateMany(ctx, inputs)
+}
+
+func (u *user) UpdateMany(ctx context.Context, where *model.UserWhereInput, set model.BulkUserPatch) ([]*model.User, error) {
+	return u.userUsecase.UpdateMany(ctx, where, set)
+}
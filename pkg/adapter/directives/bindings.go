@@ -2,51 +2,217 @@ package directives
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"reflect"
+	"strings"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/go-playground/locales"
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/ja"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/util/i18n"
 )
 
+// translation holds the locale and the default validator messages of a supported language
+type translation struct {
+	locale   func() locales.Translator
+	register func(*validator.Validate, ut.Translator) error
+}
+
+var translations = map[string]translation{
+	"en": {locale: en.New, register: en_translations.RegisterDefaultTranslations},
+	"fr": {locale: fr.New, register: fr_translations.RegisterDefaultTranslations},
+	"ja": {locale: ja.New, register: ja_translations.RegisterDefaultTranslations},
+}
+
 var (
 	validate *validator.Validate
+	uni      *ut.UniversalTranslator
 	trans    ut.Translator
 )
 
+// interfaceType is the type of the wrapper field used to validate null values
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
 func init() {
 	validate = validator.New()
-	en := en.New()
-	uni := ut.New(en, en)
-	trans, _ = uni.GetTranslator("en")
-	en_translations.RegisterDefaultTranslations(validate, trans)
+	validate.RegisterTagNameFunc(fieldName)
+
+	uni = ut.New(en.New())
+	for _, l := range i18n.Locales() {
+		t, ok := translations[l]
+		if !ok {
+			log.Fatalf("no validator translations for locale %s", l)
+		}
+		if err := uni.AddTranslator(t.locale(), true); err != nil {
+			log.Fatalf("could not add translator for locale %s: %v", l, err)
+		}
+		tr, _ := uni.GetTranslator(l)
+		if err := t.register(validate, tr); err != nil {
+			log.Fatalf("could not register validator translations for locale %s: %v", l, err)
+		}
+		for tag, message := range i18n.ValidationMessages(l) {
+			addTranslation(tr, tag, message)
+		}
+	}
+	trans, _ = uni.GetTranslator(i18n.DefaultLocale)
+
+	registerDefaultValidations()
+	registerUserValidations()
 }
 
+// Binding validates an input field or argument against the validator constraint.
+// The constraint may use the built in rules of the validator and rules added with RegisterValidation.
+// If the BindingErrors extension is in use every failing field of the input is reported,
+// otherwise the first failing field is returned as error.
 func Binding(ctx context.Context, obj interface{}, next graphql.Resolver, constraint string) (interface{}, error) {
 	val, err := next(ctx)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	field := *graphql.GetPathContext(ctx).Field
+
+	err = validateValue(field, val, constraint)
+	if err == nil {
+		return val, nil
 	}
-	fieldName := *graphql.GetPathContext(ctx).Field
 
-	err = validate.Var(val, constraint)
+	path := graphql.GetPath(ctx)
+	return reportErrors(ctx, val, err, func(validator.FieldError) ast.Path {
+		return path
+	})
+}
+
+// Validate runs the struct level validations registered for the type of an input argument.
+// The items of list arguments are validated one by one.
+// Errors are reported on the path of the offending field inside the input.
+func Validate(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	val, err := next(ctx)
 	if err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		transErr := fmt.Errorf("%s%+v", fieldName, validationErrors[0].Translate(trans))
-		return val, transErr
+		return nil, err
+	}
+
+	path := graphql.GetPath(ctx)
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return validateStruct(ctx, val, path)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		itemPath := append(append(ast.Path{}, path...), ast.PathIndex(i))
+		if _, err := validateStruct(ctx, rv.Index(i).Interface(), itemPath); err != nil {
+			return val, err
+		}
 	}
 
 	return val, nil
 }
 
+// validateStruct runs the struct level validations of the input at path
+func validateStruct(ctx context.Context, val interface{}, path ast.Path) (interface{}, error) {
+	err := validate.StructCtx(ctx, val)
+	if err == nil {
+		return val, nil
+	}
+
+	return reportErrors(ctx, val, err, func(fe validator.FieldError) ast.Path {
+		return append(append(ast.Path{}, path...), ast.PathName(fe.Field()))
+	})
+}
+
+// reportErrors converts the errors of the validator to GraphQL errors.
+// If the BindingErrors extension is in use the errors are collected and val is returned,
+// otherwise the first error is returned.
+func reportErrors(ctx context.Context, val interface{}, err error, pathOf func(validator.FieldError) ast.Path) (interface{}, error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, model.NewInternalError(err, "failed to validate input")
+	}
+
+	tr := translator(ctx)
+	errs := make(gqlerror.List, len(validationErrors))
+	for i, fe := range validationErrors {
+		errs[i] = newValidationError(pathOf(fe), fe, tr)
+	}
+
+	if c := collectorFromContext(ctx); c != nil {
+		c.add(graphql.GetFieldContext(ctx), errs...)
+		return val, nil
+	}
+
+	return val, errs[0]
+}
+
+// ValidateAddTranslation adds the message of a validator tag for the default locale.
+// Messages for other locales are loaded from the message files of i18n.
 func ValidateAddTranslation(tag string, message string) {
-	validate.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+	addTranslation(trans, tag, message)
+}
+
+func addTranslation(tr ut.Translator, tag string, message string) {
+	validate.RegisterTranslation(tag, tr, func(ut ut.Translator) error {
 		return ut.Add(tag, message, true) // see universal-translator for details
 	}, func(ut ut.Translator, fe validator.FieldError) string {
-		t, _ := ut.T(tag, fe.Field())
+		t, _ := ut.T(tag, fe.Field(), fe.Param())
 
 		return t
 	})
 }
+
+// translator returns the validator translator of the locale negotiated for the request
+func translator(ctx context.Context) ut.Translator {
+	tr, _ := uni.GetTranslator(i18n.LocaleFromContext(ctx))
+	return tr
+}
+
+// validateValue validates a single value by wrapping it in a struct,
+// so that the translated messages contain the name of the field.
+func validateValue(field string, val interface{}, constraint string) error {
+	typ := interfaceType
+	if val != nil {
+		typ = reflect.TypeOf(val)
+	}
+	t := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: typ,
+		Tag:  reflect.StructTag(fmt.Sprintf(`gql:%q validate:%q`, field, constraint)),
+	}})
+	v := reflect.New(t).Elem()
+	if val != nil {
+		v.Field(0).Set(reflect.ValueOf(val))
+	}
+
+	return validate.Struct(v.Interface())
+}
+
+// newValidationError converts a validator error to a GraphQL error located at the given path
+func newValidationError(path ast.Path, fe validator.FieldError, tr ut.Translator) *gqlerror.Error {
+	err := gqlerror.WrapPath(path, model.NewValidationError(fe, fe.Translate(tr)))
+	err.Extensions = map[string]interface{}{
+		"field": fe.Field(),
+		"rule":  fe.Tag(),
+	}
+
+	return err
+}
+
+// fieldName returns the GraphQL name of a struct field
+func fieldName(f reflect.StructField) string {
+	if name := f.Tag.Get("gql"); name != "" {
+		return name
+	}
+	if strings.ToUpper(f.Name) == f.Name {
+		return strings.ToLower(f.Name)
+	}
+	return strings.ToLower(f.Name[:1]) + f.Name[1:]
+}
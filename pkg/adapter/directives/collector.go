@@ -0,0 +1,110 @@
+package directives
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type collectorKey struct{}
+
+type argumentErrorsKey struct{}
+
+// collector gathers the @binding errors of the arguments of each field during an operation
+type collector struct {
+	mu   sync.Mutex
+	errs map[*graphql.FieldContext]gqlerror.List
+}
+
+func collectorFromContext(ctx context.Context) *collector {
+	c, _ := ctx.Value(collectorKey{}).(*collector)
+	return c
+}
+
+func (c *collector) add(fc *graphql.FieldContext, errs ...*gqlerror.Error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs[fc] = append(c.errs[fc], errs...)
+}
+
+func (c *collector) take(fc *graphql.FieldContext) gqlerror.List {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	errs := c.errs[fc]
+	delete(c.errs, fc)
+
+	return errs
+}
+
+// BindingErrors is a graphql extension which reports every failing @binding of a field at once.
+// Each failing input field becomes a separate error and the resolver of the field is not executed.
+// Fields returning a payload with userErrors are resolved anyway, their resolver takes the errors
+// with ArgumentErrors and returns them in the payload.
+type BindingErrors struct {
+	// payloads are the names of the types with a userErrors field
+	payloads map[string]bool
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.FieldInterceptor
+} = &BindingErrors{}
+
+// ExtensionName implements graphql.HandlerExtension
+func (*BindingErrors) ExtensionName() string {
+	return "BindingErrors"
+}
+
+// Validate implements graphql.HandlerExtension
+func (b *BindingErrors) Validate(schema graphql.ExecutableSchema) error {
+	b.payloads = map[string]bool{}
+	for name, def := range schema.Schema().Types {
+		if def.Kind == ast.Object && def.Fields.ForName("userErrors") != nil {
+			b.payloads[name] = true
+		}
+	}
+	return nil
+}
+
+// InterceptOperation implements graphql.OperationInterceptor
+func (*BindingErrors) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	c := &collector{errs: map[*graphql.FieldContext]gqlerror.List{}}
+	return next(context.WithValue(ctx, collectorKey{}, c))
+}
+
+// InterceptField implements graphql.FieldInterceptor
+func (b *BindingErrors) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	c := collectorFromContext(ctx)
+	if c == nil {
+		return next(ctx)
+	}
+	fc := graphql.GetFieldContext(ctx)
+	errs := c.take(fc)
+	if len(errs) == 0 {
+		return next(ctx)
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Path.String() < errs[j].Path.String()
+	})
+	if b.payloads[fc.Field.Definition.Type.Name()] {
+		return next(context.WithValue(ctx, argumentErrorsKey{}, errs))
+	}
+	for _, err := range errs[:len(errs)-1] {
+		graphql.AddError(ctx, err)
+	}
+
+	return nil, errs[len(errs)-1]
+}
+
+// ArgumentErrors returns the failing @binding of the arguments of a field returning a payload with userErrors.
+// The resolver of the field should not execute the mutation if there are any.
+func ArgumentErrors(ctx context.Context) gqlerror.List {
+	errs, _ := ctx.Value(argumentErrorsKey{}).(gqlerror.List)
+	return errs
+}
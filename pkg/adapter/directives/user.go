@@ -0,0 +1,67 @@
+package directives
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/user"
+)
+
+// registerUserValidations adds the validations of the user inputs
+func registerUserValidations() {
+	ValidateAddTranslation("excludes_name", "{0} must not contain the first or last name")
+	ValidateAddTranslation("email_changed", "{0} must differ from the current email address")
+
+	RegisterStructValidation(validateCreateUserInput, ent.CreateUserInput{})
+	RegisterStructValidation(validateUpdateUserInput, ent.UpdateUserInput{})
+}
+
+func validateCreateUserInput(_ context.Context, sl validator.StructLevel) {
+	input := sl.Current().Interface().(ent.CreateUserInput)
+
+	if containsName(input.Password, input.FirstName, input.LastName) {
+		sl.ReportError(input.Password, "password", "Password", "excludes_name", "")
+	}
+}
+
+func validateUpdateUserInput(ctx context.Context, sl validator.StructLevel) {
+	input := sl.Current().Interface().(ent.UpdateUserInput)
+	if input.Email == nil {
+		return
+	}
+	client := clientFromContext(ctx)
+	if client == nil {
+		return
+	}
+
+	// A failing lookup is left to the update itself which reports a not found error
+	current, err := client.User.Query().Where(user.ID(input.ID)).Select(user.FieldEmail).String(ctx)
+	if err != nil {
+		return
+	}
+	if strings.EqualFold(current, *input.Email) {
+		sl.ReportError(*input.Email, "email", "Email", "email_changed", "")
+	}
+}
+
+// clientFromContext returns the client of the transaction in the context, which entgql.Transactioner opens for mutations,
+// or else the client in the context. Validations which need the database are skipped without a client.
+func clientFromContext(ctx context.Context) *ent.Client {
+	if tx := ent.TxFromContext(ctx); tx != nil {
+		return tx.Client()
+	}
+	return ent.FromContext(ctx)
+}
+
+// containsName reports whether the password contains one of the names, ignoring case
+func containsName(password string, names ...string) bool {
+	p := strings.ToLower(password)
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" && strings.Contains(p, strings.ToLower(n)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package directives
+
+import (
+	"context"
+	"log"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/schema/ulid"
+)
+
+// RegisterValidation adds a named rule which can be used in @binding(constraint:).
+// The message is used for the default locale, other locales are read from the message files of i18n.
+func RegisterValidation(tag string, fn validator.FuncCtx, message string) {
+	if err := validate.RegisterValidationCtx(tag, fn); err != nil {
+		log.Fatalf("could not register validation %s: %v", tag, err)
+	}
+	ValidateAddTranslation(tag, message)
+}
+
+// RegisterStructValidation adds a validation of a whole input which is run by @validate
+// for arguments of the given types. Violations are reported with StructLevel.ReportError
+// using the GraphQL name of the field and a tag with a registered message.
+func RegisterStructValidation(fn validator.StructLevelFuncCtx, types ...interface{}) {
+	validate.RegisterStructValidationCtx(fn, types...)
+}
+
+func registerDefaultValidations() {
+	RegisterValidation("globalid", validateGlobalID, "{0} must be a valid {1} id")
+	RegisterValidation("notnull", validateNotNull, "{0} must not be null")
+}
+
+// validateNotNull rejects explicit nulls of input fields which may be omitted but not cleared.
+// It has to be the first rule of a constraint, the validator reports null values with the first rule.
+func validateNotNull(_ context.Context, fl validator.FieldLevel) bool {
+	switch f := fl.Field(); f.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Ptr, reflect.Interface:
+		return !f.IsNil()
+	default:
+		return true
+	}
+}
+
+// validateGlobalID checks that the prefix of an id belongs to the table given as parameter
+func validateGlobalID(ctx context.Context, fl validator.FieldLevel) bool {
+	t, err := ent.IDToType(ctx, ulid.ID(fl.Field().String()))
+	if err != nil {
+		return false
+	}
+	return t == fl.Param()
+}
@@ -0,0 +1,88 @@
+package directives
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/usercase/repository"
+)
+
+// InputValidator validates inputs which are not sent through GraphQL, e.g. rows of imported files,
+// with the @binding constraints of their input type in the schema and the struct validations run by @validate.
+type InputValidator struct {
+	createUserInput map[string]string
+}
+
+var _ repository.Validator = (*InputValidator)(nil)
+
+// NewInputValidator reads the constraints of the input types from the schema
+func NewInputValidator(schema *ast.Schema) *InputValidator {
+	return &InputValidator{
+		createUserInput: bindingConstraints(schema, "CreateUserInput"),
+	}
+}
+
+// ValidateCreateUserInput implements repository.Validator
+func (v *InputValidator) ValidateCreateUserInput(ctx context.Context, input model.CreateUserInput) []*model.FieldError {
+	return validateInput(ctx, v.createUserInput, input)
+}
+
+// validateInput validates every field of the input struct which has a constraint
+// and then runs the struct validations of its type
+func validateInput(ctx context.Context, constraints map[string]string, input interface{}) []*model.FieldError {
+	tr := translator(ctx)
+	var errs []*model.FieldError
+	report := func(err error) {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			errs = append(errs, &model.FieldError{Message: err.Error()})
+			return
+		}
+		for _, fe := range validationErrors {
+			errs = append(errs, &model.FieldError{Field: fe.Field(), Message: fe.Translate(tr)})
+		}
+	}
+
+	rv := reflect.ValueOf(input)
+	for i := 0; i < rv.NumField(); i++ {
+		name := fieldName(rv.Type().Field(i))
+		constraint, ok := constraints[name]
+		if !ok {
+			continue
+		}
+		if err := validateValue(name, rv.Field(i).Interface(), constraint); err != nil {
+			report(err)
+		}
+	}
+	if err := validate.StructCtx(ctx, input); err != nil {
+		report(err)
+	}
+
+	return errs
+}
+
+// bindingConstraints returns the @binding constraints of the fields of an input type by field name
+func bindingConstraints(schema *ast.Schema, input string) map[string]string {
+	def := schema.Types[input]
+	if def == nil || def.Kind != ast.InputObject {
+		log.Fatalf("input type %s is not part of the schema", input)
+	}
+
+	constraints := map[string]string{}
+	for _, f := range def.Fields {
+		d := f.Directives.ForName("binding")
+		if d == nil {
+			continue
+		}
+		if c := d.Arguments.ForName("constraint"); c != nil && c.Value != nil {
+			constraints[f.Name] = c.Value.Raw
+		}
+	}
+
+	return constraints
+}
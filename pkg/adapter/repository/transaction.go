@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"gitlab.com/trustify/core/ent"
+	usecaseRepository "gitlab.com/trustify/core/pkg/usercase/repository"
+)
+
+type transaction struct{}
+
+func NewTransaction() usecaseRepository.Transaction {
+	return transaction{}
+}
+
+// OnCommit runs fn after the transaction which entgql.Transactioner opens for mutations is committed.
+// Without one the changes of the repositories are already committed, so fn runs right away.
+func (transaction) OnCommit(ctx context.Context, fn func()) {
+	tx := ent.TxFromContext(ctx)
+	if tx == nil {
+		fn()
+		return
+	}
+
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn()
+			return nil
+		})
+	})
+}
+
+// OnRollback runs fn after the transaction in the context is rolled back
+func (transaction) OnRollback(ctx context.Context, fn func()) {
+	tx := ent.TxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	tx.OnRollback(func(next ent.Rollbacker) ent.Rollbacker {
+		return ent.RollbackFunc(func(ctx context.Context, tx *ent.Tx) error {
+			err := next.Rollback(ctx, tx)
+			fn()
+			return err
+		})
+	})
+}
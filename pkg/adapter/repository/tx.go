@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"gitlab.com/trustify/core/ent"
+)
+
+// withTx runs fn with a client of the transaction in the context, which entgql.Transactioner opens for mutations,
+// so that the changes are rolled back with the rest of the operation. Without one fn runs in a new transaction.
+func withTx(ctx context.Context, client *ent.Client, fn func(client *ent.Client) error) error {
+	if tx := ent.TxFromContext(ctx); tx != nil {
+		return fn(tx.Client())
+	}
+
+	tx, err := client.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx.Client()); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
+	"entgo.io/ent/dialect/sql"
 	"gitlab.com/trustify/core/ent"
 	"gitlab.com/trustify/core/ent/user"
 	"gitlab.com/trustify/core/pkg/entity/model"
@@ -22,31 +24,249 @@ func NewUserRepository(client *ent.Client) usecaseRepository.User {
 func (r *userRepository) Get(ctx context.Context, id *model.ID) (*model.User, error) {
 	u, err := r.client.User.Query().Where(user.IDEQ(*id)).Only(ctx)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, model.NewDBError(err, "user not found")
 	}
 	return u, nil
 }
 
 func (r *userRepository) List(ctx context.Context, after *model.Cursor, first *int, before *model.Cursor, last *int, where *model.UserWhereInput) (*model.UserConnection, error) {
-	return r.client.User.Query().Paginate(ctx, after, first, before, last, ent.WithUserFilter(where.Filter))
+	uc, err := r.client.User.Query().Paginate(ctx, after, first, before, last, ent.WithUserFilter(where.Filter))
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to list users")
+	}
+	return uc, nil
 }
 
+// Create creates the user in the transaction of the operation,
+// so that it is rolled back with the idempotency key of the operation
 func (r *userRepository) Create(ctx context.Context, input model.CreateUserInput) (*model.User, error) {
-	u, err := r.client.User.Create().SetInput(input).Save(ctx)
+	var u *model.User
+	err := withTx(ctx, r.client, func(client *ent.Client) error {
+		var err error
+		u, err = client.User.Create().SetInput(input).Save(ctx)
+		return err
+	})
 	if err != nil {
-		return nil, errors.New("failed to create user")
+		return nil, model.NewDBError(err, "failed to create user")
 	}
 	return u, nil
 }
 
+// Update changes the user with the fields of the input. If the input has an expected version,
+// the user is only updated if its version still matches, otherwise a conflict error is returned.
 func (r *userRepository) Update(ctx context.Context, input model.UpdateUserInput) (*model.User, error) {
-	u, err := r.client.User.UpdateOneID(input.ID).SetInput(input).SetUpdatedAt(time.Now()).Save(ctx)
+	var u *model.User
+	err := withTx(ctx, r.client, func(client *ent.Client) error {
+		update := client.User.Update().Where(user.ID(input.ID))
+		if input.ExpectedVersion != nil {
+			update.Where(user.Version(*input.ExpectedVersion))
+		}
+		n, err := update.SetInput(input).SetUpdatedAt(time.Now()).Save(ctx)
+		if err != nil {
+			return model.NewDBError(err, "failed to update user")
+		}
+		if n == 0 {
+			ex, err := client.User.Query().Where(user.ID(input.ID)).Exist(ctx)
+			if err != nil {
+				return model.NewDBError(err, "failed to update user")
+			}
+			if ex {
+				return model.NewConflictError(nil, "user was changed since the expected version")
+			}
+			return model.NewNotFoundError(nil, "user not found")
+		}
+
+		u, err = client.User.Get(ctx, input.ID)
+		if err != nil {
+			return model.NewDBError(err, "failed to update user")
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("failed to update user")
+		var e *model.Error
+		if errors.As(err, &e) {
+			return nil, err
+		}
+		return nil, model.NewDBError(err, "failed to update user")
 	}
 	return u, nil
 }
 
+// EmailExists reports whether a user has the email, compared case-insensitively.
+// It reads in the transaction of the operation, so that users created earlier in it are seen.
 func (r *userRepository) EmailExists(ctx context.Context, email string) (bool, error) {
-	return r.client.User.Query().Where(user.Email(email)).Exist(ctx)
+	var ex bool
+	err := withTx(ctx, r.client, func(client *ent.Client) error {
+		var err error
+		ex, err = client.User.Query().Where(user.EmailEqualFold(email)).Exist(ctx)
+		return err
+	})
+	if err != nil {
+		return false, model.NewDBError(err, "failed to check email")
+	}
+	return ex, nil
+}
+
+// ExistingEmails returns the lower case emails of the users having one of the emails, compared case-insensitively.
+// The emails are queried in batches of batchSize in the transaction of the operation.
+func (r *userRepository) ExistingEmails(ctx context.Context, emails []string, batchSize int) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	err := withTx(ctx, r.client, func(client *ent.Client) error {
+		for start := 0; start < len(emails); start += batchSize {
+			end := start + batchSize
+			if end > len(emails) {
+				end = len(emails)
+			}
+			args := make([]interface{}, 0, end-start)
+			for _, e := range emails[start:end] {
+				args = append(args, strings.ToLower(e))
+			}
+			found, err := client.User.Query().
+				Where(func(s *sql.Selector) {
+					s.Where(sql.In(sql.Lower(s.C(user.FieldEmail)), args...))
+				}).
+				Select(user.FieldEmail).
+				Strings(ctx)
+			if err != nil {
+				return err
+			}
+			for _, e := range found {
+				existing[strings.ToLower(e)] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to check emails")
+	}
+	return existing, nil
+}
+
+// UpdateAvatar sets the avatar of the user in the transaction of the operation,
+// so that it is rolled back with the operation like the stored files
+func (r *userRepository) UpdateAvatar(ctx context.Context, id model.ID, avatar string) (*model.User, error) {
+	var u *model.User
+	err := withTx(ctx, r.client, func(client *ent.Client) error {
+		var err error
+		u, err = client.User.UpdateOneID(id).SetAvatar(avatar).SetUpdatedAt(time.Now()).Save(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to update user")
+	}
+	return u, nil
+}
+
+// exportFields are the fields of users which may leave the service, the password is never loaded
+var exportFields = []string{
+	user.FieldID,
+	user.FieldFirstName,
+	user.FieldLastName,
+	user.FieldEmail,
+	user.FieldCreatedAt,
+	user.FieldUpdatedAt,
+}
+
+// Iterate calls fn with batches of at most batchSize users matching where, ordered by id.
+// Batches are loaded by keyset pagination, so the users are never held in memory at once.
+func (r *userRepository) Iterate(ctx context.Context, where *model.UserWhereInput, batchSize int, fn func(users []*model.User) error) error {
+	q, err := where.Filter(r.client.User.Query())
+	if err != nil {
+		return model.NewValidationError(err, "invalid user filter")
+	}
+
+	var last *model.ID
+	for {
+		batch := q.Clone().Order(ent.Asc(user.FieldID)).Limit(batchSize)
+		if last != nil {
+			batch.Where(user.IDGT(*last))
+		}
+		users, err := batch.Select(exportFields...).All(ctx)
+		if err != nil {
+			return model.NewDBError(err, "failed to list users")
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		if err := fn(users); err != nil {
+			return err
+		}
+		if len(users) < batchSize {
+			return nil
+		}
+		last = &users[len(users)-1].ID
+	}
+}
+
+// CreateBulk creates the users in batches of batchSize inside one transaction,
+// either all users are created or none
+func (r *userRepository) CreateBulk(ctx context.Context, inputs []model.CreateUserInput, batchSize int) ([]*model.User, error) {
+	created := make([]*model.User, 0, len(inputs))
+	err := withTx(ctx, r.client, func(client *ent.Client) error {
+		for start := 0; start < len(inputs); start += batchSize {
+			end := start + batchSize
+			if end > len(inputs) {
+				end = len(inputs)
+			}
+			builders := make([]*ent.UserCreate, 0, end-start)
+			for _, input := range inputs[start:end] {
+				builders = append(builders, client.User.Create().SetInput(input))
+			}
+			users, err := client.User.CreateBulk(builders...).Save(ctx)
+			if err != nil {
+				return err
+			}
+			created = append(created, users...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to create users")
+	}
+	return created, nil
+}
+
+// UpdateMany sets the fields of the patch on every user matching where and returns the updated users ordered by id.
+// Nothing is updated if more than max users match.
+func (r *userRepository) UpdateMany(ctx context.Context, where *model.UserWhereInput, set model.BulkUserPatch, max int) ([]*model.User, error) {
+	var updated []*model.User
+	err := withTx(ctx, r.client, func(client *ent.Client) error {
+		q, err := where.Filter(client.User.Query())
+		if err != nil {
+			return model.NewValidationError(err, "invalid user filter")
+		}
+		// the ids are selected first, the filter may not match the users any more after the update
+		ids, err := q.Order(ent.Asc(user.FieldID)).Limit(max + 1).IDs(ctx)
+		if err != nil {
+			return model.NewDBError(err, "failed to update users")
+		}
+		if len(ids) > max {
+			return model.NewValidationError(nil, "too many users match the filter")
+		}
+
+		update := client.User.Update().Where(user.IDIn(ids...)).SetUpdatedAt(time.Now())
+		if set.FirstName != nil {
+			update.SetFirstName(*set.FirstName)
+		}
+		if set.LastName != nil {
+			update.SetLastName(*set.LastName)
+		}
+		if _, err := update.Save(ctx); err != nil {
+			return model.NewDBError(err, "failed to update users")
+		}
+
+		updated, err = client.User.Query().Where(user.IDIn(ids...)).Order(ent.Asc(user.FieldID)).All(ctx)
+		if err != nil {
+			return model.NewDBError(err, "failed to update users")
+		}
+		return nil
+	})
+	if err != nil {
+		var e *model.Error
+		if errors.As(err, &e) {
+			return nil, err
+		}
+		return nil, model.NewDBError(err, "failed to update users")
+	}
+	return updated, nil
 }
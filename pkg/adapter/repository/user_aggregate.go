@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"entgo.io/ent/dialect/sql"
+	"gitlab.com/trustify/core/ent/user"
+	"gitlab.com/trustify/core/pkg/entity/model"
+)
+
+// Aliases of the aggregated columns, they match the sql tags of model.UserAggregate
+const (
+	aggregateBucket = "bucket"
+	aggregateCount  = "count"
+)
+
+// userGroupColumn is a column users can be grouped by
+type userGroupColumn struct {
+	alias string
+	expr  func(s *sql.Selector) string
+}
+
+var userGroupColumns = map[model.UserGroupField]userGroupColumn{
+	model.UserGroupFieldFirstName: {
+		alias: "first_name",
+		expr:  func(s *sql.Selector) string { return s.C(user.FieldFirstName) },
+	},
+	model.UserGroupFieldLastName: {
+		alias: "last_name",
+		expr:  func(s *sql.Selector) string { return s.C(user.FieldLastName) },
+	},
+	model.UserGroupFieldEmailDomain: {
+		alias: "email_domain",
+		expr:  func(s *sql.Selector) string { return "lower(split_part(" + s.C(user.FieldEmail) + ", '@', 2))" },
+	},
+}
+
+// aggregateIntervalUnits are the date_trunc units of the intervals
+var aggregateIntervalUnits = map[model.AggregateInterval]string{
+	model.AggregateIntervalDay:   "day",
+	model.AggregateIntervalWeek:  "week",
+	model.AggregateIntervalMonth: "month",
+}
+
+// selectAs writes expr AS alias
+func selectAs(expr, alias string) sql.Querier {
+	return sql.ExprFunc(func(b *sql.Builder) {
+		b.WriteString(expr).WriteString(" AS ").Ident(alias)
+	})
+}
+
+// Aggregate counts the users matching where per group of the groupBy fields and per creation interval.
+// Groups are ordered by interval and by the groupBy fields.
+func (r *userRepository) Aggregate(ctx context.Context, where *model.UserWhereInput, groupBy []model.UserGroupField, interval *model.AggregateInterval) ([]*model.UserAggregate, error) {
+	q, err := where.Filter(r.client.User.Query())
+	if err != nil {
+		return nil, model.NewValidationError(err, "invalid user filter")
+	}
+
+	var rows []*model.UserAggregate
+	err = q.Modify(func(s *sql.Selector) {
+		var (
+			groups    []string
+			selection []sql.Querier
+		)
+		if interval != nil {
+			trunc := "date_trunc('" + aggregateIntervalUnits[*interval] + "', " + s.C(user.FieldCreatedAt) + " AT TIME ZONE 'UTC')"
+			groups = append(groups, aggregateBucket)
+			selection = append(selection, selectAs(trunc, aggregateBucket))
+		}
+		// a column selected twice under the same alias makes GROUP BY ambiguous
+		seen := make(map[model.UserGroupField]bool, len(groupBy))
+		for _, f := range groupBy {
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			c := userGroupColumns[f]
+			groups = append(groups, c.alias)
+			selection = append(selection, selectAs(c.expr(s), c.alias))
+		}
+		selection = append(selection, selectAs(sql.Count("*"), aggregateCount))
+
+		s.Select().AppendSelectExpr(selection...)
+		if len(groups) > 0 {
+			s.GroupBy(groups...)
+			for _, g := range groups {
+				s.OrderBy(g)
+			}
+		}
+	}).Scan(ctx, &rows)
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to aggregate users")
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+
+	"entgo.io/ent/dialect/sql"
+	"gitlab.com/trustify/core/ent/predicate"
+	entschema "gitlab.com/trustify/core/ent/schema"
+	"gitlab.com/trustify/core/ent/user"
+	"gitlab.com/trustify/core/pkg/entity/model"
+)
+
+// Delimiters of matches in ts_headline. Control characters cannot be part of names,
+// so they are replaced by <mark> tags after the text was escaped.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// userSearch builds the statements of a search by full-text or by trigram similarity
+type userSearch struct {
+	tsquery string
+	query   string
+	fuzzy   bool
+}
+
+// newUserSearch returns a search matching users with words starting with every word of the query.
+// Punctuation separates words, e.g. john@doe is john & doe.
+func newUserSearch(query string) *userSearch {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+
+	return &userSearch{tsquery: strings.Join(words, " & "), query: strings.TrimSpace(query)}
+}
+
+func (s *userSearch) empty() bool {
+	return s.tsquery == ""
+}
+
+// match is the predicate of matching users.
+// The fuzzy predicate <% is word_similarity >= pg_trgm.word_similarity_threshold, which can use the trigram index.
+func (s *userSearch) match() *sql.Predicate {
+	if s.fuzzy {
+		return sql.P(func(b *sql.Builder) {
+			b.Arg(s.query).WriteString(" <% (" + entschema.UserSearchText + ")")
+		})
+	}
+	return sql.P(func(b *sql.Builder) {
+		b.WriteString(entschema.UserSearchColumn + " @@ ")
+		s.tsQuery(b)
+	})
+}
+
+// rank writes the relevance of a user as double precision, so it can be compared with cursors exactly
+func (s *userSearch) rank(b *sql.Builder) {
+	if s.fuzzy {
+		b.WriteString("word_similarity(").Arg(s.query).WriteString(", " + entschema.UserSearchText + ")::float8")
+		return
+	}
+	b.WriteString("ts_rank(" + entschema.UserSearchColumn + ", ")
+	s.tsQuery(b)
+	b.WriteString(")::float8")
+}
+
+// highlight writes the text of a user with delimited matches, NULL for fuzzy matches
+func (s *userSearch) highlight(b *sql.Builder) {
+	if s.fuzzy {
+		b.WriteString("NULL")
+		return
+	}
+	b.WriteString("ts_headline('" + entschema.UserSearchConfig + "', " + entschema.UserSearchText + ", ")
+	s.tsQuery(b)
+	b.WriteString(", ").Arg("StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", HighlightAll=true").WriteString(")")
+}
+
+func (s *userSearch) tsQuery(b *sql.Builder) {
+	b.WriteString("to_tsquery('" + entschema.UserSearchConfig + "', ").Arg(s.tsquery).WriteString(")")
+}
+
+// after is the predicate of users ranked after the cursor
+func (s *userSearch) after(c *model.Cursor) *sql.Predicate {
+	rank, _ := c.Value.(float64)
+	return sql.P(func(b *sql.Builder) {
+		b.WriteString("(")
+		s.rank(b)
+		b.WriteString(" < ").Arg(rank).WriteString(" OR (")
+		s.rank(b)
+		b.WriteString(" = ").Arg(rank).WriteString(" AND " + user.FieldID + " > ").Arg(c.ID).WriteString("))")
+	})
+}
+
+// page selects the id, rank and highlight of at most limit users ranked after the cursor
+func (s *userSearch) page(after *model.Cursor, limit int) func(*sql.Selector) {
+	return func(sel *sql.Selector) {
+		sel.Select(sel.C(user.FieldID))
+		sel.AppendSelectExpr(sql.ExprFunc(func(b *sql.Builder) {
+			s.rank(b)
+			b.WriteString(" AS rank")
+		}))
+		sel.AppendSelectExpr(sql.ExprFunc(func(b *sql.Builder) {
+			s.highlight(b)
+			b.WriteString(" AS highlight")
+		}))
+		sel.Where(s.match())
+		if after != nil {
+			sel.Where(s.after(after))
+		}
+		sel.OrderExpr(sql.Expr("rank DESC, " + sel.C(user.FieldID)))
+		sel.Limit(limit)
+	}
+}
+
+// where adapts the predicate to the ent query builders
+func where(p *sql.Predicate) predicate.User {
+	return func(s *sql.Selector) {
+		s.Where(p)
+	}
+}
+
+// formatHighlight escapes the text and marks the matches with <mark> tags
+func formatHighlight(text string) string {
+	text = html.EscapeString(text)
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(text)
+}
+
+// Search returns the page of users matching the query after the cursor.
+// If no user matches the words of the query, users with similar words are returned.
+func (r *userRepository) Search(ctx context.Context, query string, first int, after *model.Cursor) (*model.UserSearchConnection, error) {
+	conn := &model.UserSearchConnection{Edges: []*model.UserSearchEdge{}}
+	s := newUserSearch(query)
+	if s.empty() {
+		return conn, nil
+	}
+
+	var err error
+
+	conn.TotalCount, err = r.client.User.Query().Where(where(s.match())).Count(ctx)
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to search users")
+	}
+	if conn.TotalCount == 0 {
+		s.fuzzy = true
+		conn.Fuzzy = true
+		conn.TotalCount, err = r.client.User.Query().Where(where(s.match())).Count(ctx)
+		if err != nil {
+			return nil, model.NewDBError(err, "failed to search users")
+		}
+	}
+
+	var rows []struct {
+		ID        model.ID       `sql:"id"`
+		Rank      float64        `sql:"rank"`
+		Highlight sql.NullString `sql:"highlight"`
+	}
+	if err := r.client.User.Query().Modify(s.page(after, first+1)).Scan(ctx, &rows); err != nil {
+		return nil, model.NewDBError(err, "failed to search users")
+	}
+
+	if len(rows) > first {
+		rows = rows[:first]
+		conn.PageInfo.HasNextPage = true
+	}
+	conn.PageInfo.HasPreviousPage = after != nil
+
+	ids := make([]model.ID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	users, err := r.client.User.Query().Where(user.IDIn(ids...)).All(ctx)
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to search users")
+	}
+	byID := make(map[model.ID]*model.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	for _, row := range rows {
+		u, ok := byID[row.ID]
+		if !ok {
+			// deleted after it was found
+			continue
+		}
+		edge := &model.UserSearchEdge{
+			Node:   u,
+			Cursor: model.Cursor{ID: row.ID, Value: row.Rank},
+			Rank:   row.Rank,
+		}
+		if row.Highlight.Valid {
+			h := formatHighlight(row.Highlight.String)
+			edge.Highlight = &h
+		}
+		conn.Edges = append(conn.Edges, edge)
+	}
+	if n := len(conn.Edges); n > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[n-1].Cursor
+	}
+
+	return conn, nil
+}
@@ -103,6 +103,7 @@ func TestUserRepository__Get(t *testing.T) {
 				assert.Nil(t, u)
 				assert.NotNil(t, err)
 				assert.Equal(t, err.Error(), "user not found")
+				assert.Equal(t, model.CodeNotFound, model.ErrorCodeOf(err))
 			},
 			args: args{
 				ctx: context.Background(),
@@ -290,6 +291,7 @@ func TestUserRepository__Create(t *testing.T) {
 			assert: func(t *testing.T, got *model.User, err error) {
 				assert.Nil(t, got)
 				assert.Equal(t, "failed to create user", err.Error())
+				assert.Equal(t, model.CodeConflict, model.ErrorCodeOf(err))
 			},
 			args: args{
 				ctx: context.Background(),
@@ -420,6 +422,49 @@ func TestUserRepository__Update(t *testing.T) {
 			assert: func(t *testing.T, got *model.User, err error) {
 				assert.Nil(t, got)
 				assert.Equal(t, "failed to update user", err.Error())
+				assert.Equal(t, model.CodeConflict, model.ErrorCodeOf(err))
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should return a conflict error if the version changed",
+			arrange: func(t *testing.T) model.ID {
+				ctx := context.Background()
+				u, err := repo.Create(ctx, model.CreateUserInput{
+					FirstName: "John",
+					LastName:  "Doe",
+					Email:     "john@yourname.xyz",
+					Password:  "secret",
+				})
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				lastName := "Sparrow"
+				_, err = repo.Update(ctx, model.UpdateUserInput{ID: u.ID, LastName: &lastName})
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				return u.ID
+			},
+			act: func(ctx context.Context, _ *testing.T, id model.ID) (us *model.User, err error) {
+				lastName, version := "Smith", 1
+				return repo.Update(ctx, model.UpdateUserInput{
+					ID:              id,
+					LastName:        &lastName,
+					ExpectedVersion: &version,
+				})
+			},
+			assert: func(t *testing.T, got *model.User, err error) {
+				assert.Nil(t, got)
+				assert.Equal(t, "user was changed since the expected version", err.Error())
+				assert.Equal(t, model.CodeConflict, model.ErrorCodeOf(err))
 			},
 			args: args{
 				ctx: context.Background(),
@@ -520,6 +565,35 @@ func TestUserRepository__EmailExists(t *testing.T) {
 				testutil.DropUser(t, client)
 			},
 		},
+		{
+			name: "it return true if a user has the email in another case",
+			arrange: func(t *testing.T) {
+				ctx := context.Background()
+				_, err := repo.Create(ctx, model.CreateUserInput{
+					FirstName: "John",
+					LastName:  "Doe",
+					Email:     "John@YourName.xyz",
+					Password:  "secret",
+				})
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+			},
+			act: func(ctx context.Context, _ *testing.T) (exists bool, err error) {
+				return repo.EmailExists(ctx, "john@yourname.xyz")
+			},
+			assert: func(t *testing.T, got bool, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, true, got)
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -531,3 +605,194 @@ func TestUserRepository__EmailExists(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepository__ExistingEmails(t *testing.T) {
+	t.Helper()
+
+	client, teardown := setup(t)
+	defer teardown()
+
+	repo := repository.NewUserRepository(client)
+
+	type args struct {
+		ctx       context.Context
+		emails    []string
+		batchSize int
+	}
+
+	tests := []struct {
+		name     string
+		arrange  func(t *testing.T)
+		act      func(ctx context.Context, t *testing.T, emails []string, batchSize int) (map[string]bool, error)
+		assert   func(t *testing.T, got map[string]bool, err error)
+		args     args
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should return the existing emails in lower case",
+			arrange: func(t *testing.T) {
+				ctx := context.Background()
+				for _, email := range []string{"john@yourname.xyz", "Jane@YourName.xyz"} {
+					_, err := repo.Create(ctx, model.CreateUserInput{
+						FirstName: "John",
+						LastName:  "Doe",
+						Email:     email,
+						Password:  "secret",
+					})
+					if err != nil {
+						t.Error(err)
+						t.FailNow()
+					}
+				}
+			},
+			act: func(ctx context.Context, _ *testing.T, emails []string, batchSize int) (map[string]bool, error) {
+				return repo.ExistingEmails(ctx, emails, batchSize)
+			},
+			assert: func(t *testing.T, got map[string]bool, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, map[string]bool{"john@yourname.xyz": true, "jane@yourname.xyz": true}, got)
+			},
+			args: args{
+				ctx:       context.Background(),
+				emails:    []string{"JOHN@yourname.xyz", "jack@yourname.xyz", "jane@yourname.xyz"},
+				batchSize: 2,
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got, err := tt.act(tt.args.ctx, t, tt.args.emails, tt.args.batchSize)
+			tt.assert(t, got, err)
+			tt.teardown(t)
+		})
+	}
+}
+
+func TestUserRepository__UpdateAvatar(t *testing.T) {
+	t.Helper()
+
+	client, teardown := setup(t)
+	defer teardown()
+
+	repo := repository.NewUserRepository(client)
+
+	type args struct {
+		ctx context.Context
+	}
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T) model.ID
+		act     func(ctx context.Context, t *testing.T, id model.ID) (uc *model.User, err error)
+		assert  func(t *testing.T, u *model.User, err error)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should update the avatar",
+			arrange: func(t *testing.T) model.ID {
+				u, err := repo.Create(context.Background(), model.CreateUserInput{
+					FirstName: "John",
+					LastName:  "Doe",
+					Email:     "john@yourname.xyz",
+					Password:  "secret",
+				})
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				return u.ID
+			},
+			act: func(ctx context.Context, _ *testing.T, id model.ID) (us *model.User, err error) {
+				return repo.UpdateAvatar(ctx, id, "avatars/usr_1/avatar.png")
+			},
+			assert: func(t *testing.T, got *model.User, err error) {
+				assert.Nil(t, err)
+				if assert.NotNil(t, got.Avatar) {
+					assert.Equal(t, "avatars/usr_1/avatar.png", *got.Avatar)
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should roll back the avatar with the transaction in the context",
+			arrange: func(t *testing.T) model.ID {
+				u, err := repo.Create(context.Background(), model.CreateUserInput{
+					FirstName: "John",
+					LastName:  "Doe",
+					Email:     "john@yourname.xyz",
+					Password:  "secret",
+				})
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				return u.ID
+			},
+			act: func(ctx context.Context, t *testing.T, id model.ID) (us *model.User, err error) {
+				tx, err := client.Tx(ctx)
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				if _, err := repo.UpdateAvatar(ent.NewTxContext(ctx, tx), id, "avatars/usr_1/avatar.png"); err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				if err := tx.Rollback(); err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				return repo.Get(ctx, &id)
+			},
+			assert: func(t *testing.T, got *model.User, err error) {
+				assert.Nil(t, err)
+				assert.Nil(t, got.Avatar)
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should return not found for unknown users",
+			arrange: func(t *testing.T) model.ID {
+				return model.ID("usr_unknown")
+			},
+			act: func(ctx context.Context, _ *testing.T, id model.ID) (us *model.User, err error) {
+				return repo.UpdateAvatar(ctx, id, "avatars/usr_1/avatar.png")
+			},
+			assert: func(t *testing.T, got *model.User, err error) {
+				assert.Nil(t, got)
+				assert.Equal(t, model.CodeNotFound, model.ErrorCodeOf(err))
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := tt.arrange(t)
+			got, err := tt.act(tt.args.ctx, t, ar)
+			tt.assert(t, got, err)
+			tt.teardown(t)
+		})
+	}
+}
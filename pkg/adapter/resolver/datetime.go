@@ -0,0 +1,17 @@
+package resolver
+
+import (
+	"time"
+
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/util/datetime"
+)
+
+// newDateTime converts a timestamp of an entity to the timezone and format requested by the client
+func newDateTime(t time.Time, timezone *string, format *datetime.Format) (*datetime.DateTime, error) {
+	dt, err := datetime.New(t, timezone, format)
+	if err != nil {
+		return nil, model.NewValidationError(err, "invalid timezone")
+	}
+	return dt, nil
+}
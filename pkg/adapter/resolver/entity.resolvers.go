@@ -0,0 +1,39 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/schema/ulid"
+	"gitlab.com/trustify/core/ent/user"
+	"gitlab.com/trustify/core/graph/generated"
+)
+
+func (r *entityResolver) FindManyUserByIDs(ctx context.Context, reps []*generated.UserByIDsInput) ([]*ent.User, error) {
+	ids := make([]ulid.ID, len(reps))
+	for i, rep := range reps {
+		ids[i] = rep.ID
+	}
+	found, err := r.client.User.Query().Where(user.IDIn(ids...)).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[ulid.ID]*ent.User, len(found))
+	for _, u := range found {
+		byID[u.ID] = u
+	}
+
+	users := make([]*ent.User, len(ids))
+	for i, id := range ids {
+		users[i] = byID[id]
+	}
+	return users, nil
+}
+
+// Entity returns generated.EntityResolver implementation.
+func (r *Resolver) Entity() generated.EntityResolver { return &entityResolver{r} }
+
+type entityResolver struct{ *Resolver }
@@ -0,0 +1,15 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// rawInput returns an input object argument of the current field as sent by the client.
+// Unlike the unmarshalled input it tells omitted fields from fields which are explicitly null.
+func rawInput(ctx context.Context, name string) map[string]interface{} {
+	args := graphql.GetFieldContext(ctx).Field.ArgumentMap(graphql.GetOperationContext(ctx).Variables)
+	raw, _ := args[name].(map[string]interface{})
+	return raw
+}
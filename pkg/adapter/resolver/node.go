@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"context"
+
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/schema/ulid"
+	"gitlab.com/trustify/core/ent/user"
+)
+
+// nodes resolves the ids with one query per table and returns the nodes in the order of the ids.
+// An id with an unknown prefix or of a missing node resolves to nil instead of failing the whole list.
+func (r *Resolver) nodes(ctx context.Context, ids []ulid.ID) ([]ent.Noder, error) {
+	tables := make(map[string][]ulid.ID)
+	for _, id := range ids {
+		table, err := ent.IDToType(ctx, id)
+		if err != nil {
+			continue
+		}
+		tables[table] = append(tables[table], id)
+	}
+
+	byID := make(map[ulid.ID]ent.Noder, len(ids))
+	for table, tableIDs := range tables {
+		switch table {
+		case user.Table:
+			users, err := r.client.User.Query().Where(user.IDIn(tableIDs...)).CollectFields(ctx, "User").All(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, u := range users {
+				byID[u.ID] = u
+			}
+		}
+	}
+
+	nodes := make([]ent.Noder, len(ids))
+	for i, id := range ids {
+		nodes[i] = byID[id]
+	}
+
+	return nodes, nil
+}
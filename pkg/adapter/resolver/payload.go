@@ -0,0 +1,89 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gitlab.com/trustify/core/pkg/adapter/directives"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/util/i18n"
+)
+
+// mutate runs a mutation unless its arguments failed validation.
+// Expected failures, i.e. errors with the code VALIDATION, CONFLICT or NOT_FOUND, are returned as user errors
+// for the payload, any other error is returned as error and becomes a GraphQL error.
+func mutate[T any](ctx context.Context, fn func() (T, error)) (T, []*model.UserError, error) {
+	var zero T
+	if errs := directives.ArgumentErrors(ctx); len(errs) > 0 {
+		return zero, userErrors(ctx, errs), nil
+	}
+
+	v, err := fn()
+	if err == nil {
+		return v, []*model.UserError{}, nil
+	}
+	var errs gqlerror.List
+	if !errors.As(err, &errs) {
+		errs = gqlerror.List{gqlerror.WrapPath(graphql.GetPath(ctx), err)}
+	}
+	for _, e := range errs {
+		switch model.ErrorCodeOf(e) {
+		case model.CodeValidation, model.CodeConflict, model.CodeNotFound:
+		default:
+			return zero, nil, e
+		}
+	}
+
+	return zero, userErrors(ctx, errs), nil
+}
+
+// itemErrors locates the errors of a model.BulkError at their item in the list argument, e.g. ["inputs", "2", "email"].
+// Other errors are returned unchanged.
+func itemErrors(ctx context.Context, argument string, err error) error {
+	var bulkErr *model.BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Errors) == 0 {
+		return err
+	}
+
+	path := graphql.GetPath(ctx)
+	errs := make(gqlerror.List, len(bulkErr.Errors))
+	for i, e := range bulkErr.Errors {
+		p := append(append(ast.Path{}, path...), ast.PathName(argument), ast.PathIndex(e.Index))
+		if e.Field != "" {
+			p = append(p, ast.PathName(e.Field))
+		}
+		errs[i] = gqlerror.WrapPath(p, e.Err)
+	}
+
+	return errs
+}
+
+// userErrors converts GraphQL errors located in the arguments of the current field to user errors
+func userErrors(ctx context.Context, errs gqlerror.List) []*model.UserError {
+	locale := i18n.LocaleFromContext(ctx)
+	path := graphql.GetPath(ctx)
+	userErrs := make([]*model.UserError, len(errs))
+	for i, e := range errs {
+		userErrs[i] = &model.UserError{
+			Code:    string(model.ErrorCodeOf(e)),
+			Message: i18n.Translate(locale, e.Message),
+		}
+		if len(e.Path) <= len(path) {
+			continue
+		}
+		for _, p := range e.Path[len(path):] {
+			switch p := p.(type) {
+			case ast.PathName:
+				userErrs[i].Field = append(userErrs[i].Field, string(p))
+			case ast.PathIndex:
+				userErrs[i].Field = append(userErrs[i].Field, strconv.Itoa(int(p)))
+			}
+		}
+	}
+
+	return userErrs
+}
@@ -27,6 +27,7 @@ func NewSchema(client *ent.Client, controller controller.Controller) graphql.Exe
 		},
 	}
 	c.Directives.Binding = directives.Binding
+	c.Directives.Validate = directives.Validate
 
 	return generated.NewExecutableSchema(c)
 }
@@ -5,16 +5,26 @@ package resolver
 
 import (
 	"context"
+	"fmt"
 
+	"gitlab.com/trustify/core/config"
 	"gitlab.com/trustify/core/ent"
 	"gitlab.com/trustify/core/ent/schema/ulid"
 	"gitlab.com/trustify/core/graph/generated"
+	"gitlab.com/trustify/core/pkg/entity/model"
 )
 
 func (r *queryResolver) Node(ctx context.Context, id ulid.ID) (ent.Noder, error) {
 	return r.client.Noder(ctx, id, ent.WithNodeType(ent.IDToType))
 }
 
+func (r *queryResolver) Nodes(ctx context.Context, ids []ulid.ID) ([]ent.Noder, error) {
+	if len(ids) > config.C.GraphQL.MaxNodes {
+		return nil, model.NewValidationError(fmt.Errorf("%d ids exceed the limit of %d", len(ids), config.C.GraphQL.MaxNodes), "too many ids requested")
+	}
+	return r.nodes(ctx, ids)
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
@@ -6,18 +6,74 @@ package resolver
 import (
 	"context"
 
+	"github.com/99designs/gqlgen/graphql"
 	"gitlab.com/trustify/core/ent"
 	"gitlab.com/trustify/core/ent/schema/ulid"
 	"gitlab.com/trustify/core/graph/generated"
+	"gitlab.com/trustify/core/pkg/entity/model"
 	"gitlab.com/trustify/core/pkg/util/datetime"
 )
 
-func (r *mutationResolver) CreateUser(ctx context.Context, input ent.CreateUserInput) (*ent.User, error) {
-	return r.controller.User.Create(ctx, input)
+func (r *mutationResolver) CreateUser(ctx context.Context, input ent.CreateUserInput) (*ent.CreateUserPayload, error) {
+	u, errs, err := mutate(ctx, func() (*ent.User, error) {
+		return r.controller.User.Create(ctx, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ent.CreateUserPayload{User: u, UserErrors: errs}, nil
 }
 
-func (r *mutationResolver) UpdateUser(ctx context.Context, input ent.UpdateUserInput) (*ent.User, error) {
-	return r.controller.User.Update(ctx, input)
+func (r *mutationResolver) UpdateUser(ctx context.Context, input ent.UpdateUserInput) (*ent.UpdateUserPayload, error) {
+	input.ClearNulls(rawInput(ctx, "input"))
+	u, errs, err := mutate(ctx, func() (*ent.User, error) {
+		return r.controller.User.Update(ctx, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ent.UpdateUserPayload{User: u, UserErrors: errs}, nil
+}
+
+func (r *mutationResolver) UploadAvatar(ctx context.Context, id ulid.ID, file graphql.Upload) (*model.UploadAvatarPayload, error) {
+	u, errs, err := mutate(ctx, func() (*ent.User, error) {
+		return r.controller.User.UploadAvatar(ctx, id, file)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.UploadAvatarPayload{User: u, UserErrors: errs}, nil
+}
+
+func (r *mutationResolver) ImportUsers(ctx context.Context, file graphql.Upload, dryRun *bool) (*model.ImportUsersPayload, error) {
+	report, errs, err := mutate(ctx, func() (*model.ImportUsersReport, error) {
+		return r.controller.User.Import(ctx, file.File, dryRun != nil && *dryRun)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.ImportUsersPayload{Report: report, UserErrors: errs}, nil
+}
+
+func (r *mutationResolver) CreateUsers(ctx context.Context, inputs []*ent.CreateUserInput) (*model.CreateUsersPayload, error) {
+	users, errs, err := mutate(ctx, func() ([]*ent.User, error) {
+		users, err := r.controller.User.CreateMany(ctx, inputs)
+		return users, itemErrors(ctx, "inputs", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateUsersPayload{Users: users, UserErrors: errs}, nil
+}
+
+func (r *mutationResolver) UpdateUsers(ctx context.Context, where ent.UserWhereInput, set model.BulkUserPatch) (*model.UpdateUsersPayload, error) {
+	users, errs, err := mutate(ctx, func() ([]*ent.User, error) {
+		return r.controller.User.UpdateMany(ctx, &where, set)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.UpdateUsersPayload{Users: users, UserErrors: errs}, nil
 }
 
 func (r *queryResolver) User(ctx context.Context, id *ulid.ID) (*ent.User, error) {
@@ -28,15 +84,62 @@ func (r *queryResolver) Users(ctx context.Context, after *ent.Cursor, first *int
 	return r.controller.User.List(ctx, after, first, before, last, where)
 }
 
-func (r *userResolver) CreatedAt(ctx context.Context, obj *ent.User) (string, error) {
-	return datetime.FormatDate(obj.CreatedAt), nil
+func (r *queryResolver) SearchUsers(ctx context.Context, query string, first *int, after *ent.Cursor) (*model.UserSearchConnection, error) {
+	return r.controller.User.Search(ctx, query, first, after)
+}
+
+func (r *queryResolver) UsersAggregate(ctx context.Context, where *ent.UserWhereInput, groupBy []model.UserGroupField, interval *model.AggregateInterval) ([]*model.UserAggregate, error) {
+	return r.controller.User.Aggregate(ctx, where, groupBy, interval)
+}
+
+func (r *userResolver) AvatarURL(ctx context.Context, obj *ent.User, thumbnail *bool) (*string, error) {
+	return r.controller.User.AvatarURL(ctx, obj, thumbnail != nil && *thumbnail)
+}
+
+func (r *userResolver) CreatedAt(ctx context.Context, obj *ent.User, timezone *string, format *datetime.Format) (*datetime.DateTime, error) {
+	return newDateTime(obj.CreatedAt, timezone, format)
 }
 
-func (r *userResolver) UpdatedAt(ctx context.Context, obj *ent.User) (string, error) {
-	return datetime.FormatDate(obj.UpdatedAt), nil
+func (r *userResolver) UpdatedAt(ctx context.Context, obj *ent.User, timezone *string, format *datetime.Format) (*datetime.DateTime, error) {
+	return newDateTime(obj.UpdatedAt, timezone, format)
+}
+
+func (r *userAggregateResolver) Bucket(ctx context.Context, obj *model.UserAggregate, format *datetime.Format) (*datetime.DateTime, error) {
+	if obj.Bucket == nil {
+		return nil, nil
+	}
+	return newDateTime(obj.Bucket.UTC(), nil, format)
+}
+
+func (r *userSearchConnectionResolver) PageInfo(ctx context.Context, obj *model.UserSearchConnection) (*ent.PageInfo, error) {
+	return &obj.PageInfo, nil
+}
+
+func (r *userSearchEdgeResolver) Node(ctx context.Context, obj *model.UserSearchEdge) (*ent.User, error) {
+	return obj.Node, nil
+}
+
+func (r *userSearchEdgeResolver) Cursor(ctx context.Context, obj *model.UserSearchEdge) (*ent.Cursor, error) {
+	return &obj.Cursor, nil
 }
 
 // User returns generated.UserResolver implementation.
 func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
 
+// UserAggregate returns generated.UserAggregateResolver implementation.
+func (r *Resolver) UserAggregate() generated.UserAggregateResolver { return &userAggregateResolver{r} }
+
+// UserSearchConnection returns generated.UserSearchConnectionResolver implementation.
+func (r *Resolver) UserSearchConnection() generated.UserSearchConnectionResolver {
+	return &userSearchConnectionResolver{r}
+}
+
+// UserSearchEdge returns generated.UserSearchEdgeResolver implementation.
+func (r *Resolver) UserSearchEdge() generated.UserSearchEdgeResolver {
+	return &userSearchEdgeResolver{r}
+}
+
 type userResolver struct{ *Resolver }
+type userAggregateResolver struct{ *Resolver }
+type userSearchConnectionResolver struct{ *Resolver }
+type userSearchEdgeResolver struct{ *Resolver }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"reflect"
 
+	"gitlab.com/trustify/core/ent/idempotencykey"
 	"gitlab.com/trustify/core/ent/user"
 )
 
@@ -15,7 +16,8 @@ type field struct {
 
 // GlobalIDs maps unique string to table names
 type GlobalIDs struct {
-	User field
+	User           field
+	IdempotencyKey field
 }
 
 // New generates a map object that is intended to be used as global identification for node interface query.
@@ -26,6 +28,10 @@ func New() GlobalIDs {
 			Prefix: "usr_",
 			Table:  user.Table,
 		},
+		IdempotencyKey: field{
+			Prefix: "idk_",
+			Table:  idempotencykey.Table,
+		},
 	}
 }
 
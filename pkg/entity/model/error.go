@@ -0,0 +1,105 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"gitlab.com/trustify/core/ent"
+)
+
+// ErrorCode is a machine readable code exposed to clients in the extensions of a GraphQL error
+type ErrorCode string
+
+// Error codes
+const (
+	CodeNotFound     ErrorCode = "NOT_FOUND"
+	CodeConflict     ErrorCode = "CONFLICT"
+	CodeValidation   ErrorCode = "VALIDATION"
+	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	CodeInternal     ErrorCode = "INTERNAL"
+)
+
+// Error is a domain error. Message is safe to be shown to clients,
+// Err holds the original cause and is only meant for logging.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the original cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewNotFoundError returns an error for a resource which does not exist
+func NewNotFoundError(err error, message string) error {
+	return &Error{Code: CodeNotFound, Message: message, Err: err}
+}
+
+// NewConflictError returns an error for a request conflicting with the current state of a resource
+func NewConflictError(err error, message string) error {
+	return &Error{Code: CodeConflict, Message: message, Err: err}
+}
+
+// NewValidationError returns an error for invalid input
+func NewValidationError(err error, message string) error {
+	return &Error{Code: CodeValidation, Message: message, Err: err}
+}
+
+// NewUnauthorizedError returns an error for a request without sufficient permissions
+func NewUnauthorizedError(err error, message string) error {
+	return &Error{Code: CodeUnauthorized, Message: message, Err: err}
+}
+
+// NewInternalError returns an error for an unexpected failure
+func NewInternalError(err error, message string) error {
+	return &Error{Code: CodeInternal, Message: message, Err: err}
+}
+
+// NewDBError maps an error returned by ent to a domain error with the given message
+func NewDBError(err error, message string) error {
+	switch {
+	case ent.IsNotFound(err):
+		return NewNotFoundError(err, message)
+	case ent.IsConstraintError(err):
+		return NewConflictError(err, message)
+	case ent.IsValidationError(err):
+		return NewValidationError(err, message)
+	default:
+		return NewInternalError(err, message)
+	}
+}
+
+// ItemError is an error of one item of a list input
+type ItemError struct {
+	// Index of the item in the list
+	Index int
+	// Field of the item causing the error, empty if the error concerns the whole item
+	Field string
+	Err   error
+}
+
+// BulkError holds the errors of the items of a bulk mutation, nothing is written if it is returned
+type BulkError struct {
+	Errors []*ItemError
+}
+
+// Error implements the error interface
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("%d items failed", len(e.Errors))
+}
+
+// ErrorCodeOf returns the code of a domain error. Any other error is considered internal.
+func ErrorCodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
@@ -1,6 +1,9 @@
 package model
 
-import "gitlab.com/trustify/core/ent"
+import (
+	"github.com/99designs/gqlgen/graphql"
+	"gitlab.com/trustify/core/ent"
+)
 
 type User = ent.User
 
@@ -11,3 +14,54 @@ type CreateUserInput = ent.CreateUserInput
 type UpdateUserInput = ent.UpdateUserInput
 
 type UserWhereInput = ent.UserWhereInput
+
+type Upload = graphql.Upload
+
+// UserSearchConnection is a page of the users matching a search query, ordered by rank
+type UserSearchConnection struct {
+	TotalCount int
+	PageInfo   PageInfo
+	Edges      []*UserSearchEdge
+	// Fuzzy is set if no user matched the words of the query and users with similar words are returned
+	Fuzzy bool
+}
+
+// UserSearchEdge is a user matching a search query
+type UserSearchEdge struct {
+	Node   *User
+	Cursor Cursor
+	// Rank is the relevance of the user, higher is better
+	Rank float64
+	// Highlight is the text of the user with the matching words in <mark> tags, nil for fuzzy matches
+	Highlight *string
+}
+
+type UserError = ent.UserError
+
+type CreateUserPayload = ent.CreateUserPayload
+
+type UpdateUserPayload = ent.UpdateUserPayload
+
+// UploadAvatarPayload is the result of the uploadAvatar mutation, User is nil if UserErrors is not empty
+type UploadAvatarPayload struct {
+	User       *ent.User
+	UserErrors []*ent.UserError
+}
+
+// CreateUsersPayload is the result of the createUsers mutation, Users is nil if UserErrors is not empty
+type CreateUsersPayload struct {
+	Users      []*ent.User
+	UserErrors []*ent.UserError
+}
+
+// UpdateUsersPayload is the result of the updateUsers mutation, Users is nil if UserErrors is not empty
+type UpdateUsersPayload struct {
+	Users      []*ent.User
+	UserErrors []*ent.UserError
+}
+
+// ImportUsersPayload is the result of the importUsers mutation, Report is nil if the file could not be read
+type ImportUsersPayload struct {
+	Report     *ImportUsersReport
+	UserErrors []*ent.UserError
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// UserGroupField is a property users are grouped by in aggregations
+type UserGroupField string
+
+// Supported group fields
+const (
+	UserGroupFieldFirstName   UserGroupField = "FIRST_NAME"
+	UserGroupFieldLastName    UserGroupField = "LAST_NAME"
+	UserGroupFieldEmailDomain UserGroupField = "EMAIL_DOMAIN"
+)
+
+// IsValid reports whether f is a supported group field
+func (f UserGroupField) IsValid() bool {
+	switch f {
+	case UserGroupFieldFirstName, UserGroupFieldLastName, UserGroupFieldEmailDomain:
+		return true
+	}
+	return false
+}
+
+// MarshalGQL implements the graphql.Marshaler interface
+func (f UserGroupField) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(f)))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface
+func (f *UserGroupField) UnmarshalGQL(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("user group field must be a string %v", v)
+	}
+	*f = UserGroupField(s)
+	if !f.IsValid() {
+		return fmt.Errorf("%s is not a valid UserGroupField", s)
+	}
+
+	return nil
+}
+
+// AggregateInterval is the length of the time buckets of aggregations
+type AggregateInterval string
+
+// Supported intervals
+const (
+	AggregateIntervalDay   AggregateInterval = "DAY"
+	AggregateIntervalWeek  AggregateInterval = "WEEK"
+	AggregateIntervalMonth AggregateInterval = "MONTH"
+)
+
+// IsValid reports whether i is a supported interval
+func (i AggregateInterval) IsValid() bool {
+	switch i {
+	case AggregateIntervalDay, AggregateIntervalWeek, AggregateIntervalMonth:
+		return true
+	}
+	return false
+}
+
+// MarshalGQL implements the graphql.Marshaler interface
+func (i AggregateInterval) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(i)))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface
+func (i *AggregateInterval) UnmarshalGQL(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("aggregate interval must be a string %v", v)
+	}
+	*i = AggregateInterval(s)
+	if !i.IsValid() {
+		return fmt.Errorf("%s is not a valid AggregateInterval", s)
+	}
+
+	return nil
+}
+
+// UserAggregate is the number of users in a group.
+// Only the properties the users were grouped by are set.
+type UserAggregate struct {
+	FirstName   *string `sql:"first_name"`
+	LastName    *string `sql:"last_name"`
+	EmailDomain *string `sql:"email_domain"`
+	// Bucket is the start of the creation time interval in UTC
+	Bucket *time.Time `sql:"bucket"`
+	Count  int        `sql:"count"`
+}
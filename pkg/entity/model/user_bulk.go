@@ -0,0 +1,12 @@
+package model
+
+// BulkUserPatch holds the fields set on every user matched by updateUsers, nil fields are left unchanged
+type BulkUserPatch struct {
+	FirstName *string
+	LastName  *string
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p BulkUserPatch) IsEmpty() bool {
+	return p.FirstName == nil && p.LastName == nil
+}
@@ -0,0 +1,28 @@
+package model
+
+// ImportUsersReport is the result of an import of users from a CSV file.
+// Users are only created if no row has errors.
+type ImportUsersReport struct {
+	DryRun bool
+	// Rows is the number of data rows in the file
+	Rows int
+	// Imported is the number of created users, 0 for dry runs and files with errors
+	Imported int
+	Errors   []*ImportUserError
+}
+
+// ImportUserError is a problem of a row which prevents the import
+type ImportUserError struct {
+	// Row is the line of the row in the file, the header is line 1
+	Row int
+	// Field is the column of the problem, nil if it concerns the whole row
+	Field   *string
+	Message string
+}
+
+// FieldError is a violated validation rule of an input field.
+// Message is translated to the locale of the context it was validated in.
+type FieldError struct {
+	Field   string
+	Message string
+}
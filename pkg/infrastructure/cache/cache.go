@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitlab.com/trustify/core/config"
+)
+
+// Stores of cached responses
+const (
+	StoreNone   = "none"
+	StoreMemory = "memory"
+)
+
+// Entry is a cached response
+type Entry struct {
+	ContentType  string
+	CacheControl string
+	Body         []byte
+	// Types contained in the response, the entry is invalidated if an entity of one of the types changes
+	Types []string
+}
+
+// Cache stores full responses of queries.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the entry of the key if it has not expired
+	Get(ctx context.Context, key string) (*Entry, bool)
+	// Set stores the entry for ttl
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration)
+	// Invalidate removes every entry containing one of the types
+	Invalidate(ctx context.Context, types ...string)
+}
+
+// New returns the cache configured in config.C.Cache or nil if responses are not cached
+func New() (Cache, error) {
+	switch config.C.Cache.Store {
+	case StoreNone, "":
+		return nil, nil
+	case StoreMemory:
+		return NewMemory(config.C.Cache.MaxEntries), nil
+	default:
+		return nil, fmt.Errorf("unknown cache store %q", config.C.Cache.Store)
+	}
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+
+	"gitlab.com/trustify/core/ent"
+)
+
+// InvalidationHook returns an ent hook invalidating the cached responses containing the type of every mutated entity.
+// Inside a transaction the responses are invalidated after the commit.
+func InvalidationHook(c Cache) ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			v, err := next.Mutate(ctx, m)
+			if err != nil {
+				return v, err
+			}
+
+			typ := m.Type()
+			if txm, ok := m.(interface{ Tx() (*ent.Tx, error) }); ok {
+				if tx, err := txm.Tx(); err == nil {
+					tx.OnCommit(func(next ent.Committer) ent.Committer {
+						return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+							if err := next.Commit(ctx, tx); err != nil {
+								return err
+							}
+							c.Invalidate(ctx, typ)
+							return nil
+						})
+					})
+					return v, nil
+				}
+			}
+			c.Invalidate(ctx, typ)
+
+			return v, nil
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry is an entry with its expiry
+type memoryEntry struct {
+	*Entry
+	expires time.Time
+}
+
+// Memory is a Cache in the memory of the process.
+// If the cache is full, expired entries are removed first and then the entry which expires next.
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryEntry
+}
+
+var _ Cache = (*Memory)(nil)
+
+// NewMemory returns a Memory cache holding at most maxEntries entries, 0 means unlimited
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{maxEntries: maxEntries, entries: map[string]memoryEntry{}}
+}
+
+// Get implements Cache
+func (m *Memory) Get(_ context.Context, key string) (*Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.Entry, true
+}
+
+// Set implements Cache
+func (m *Memory) Set(_ context.Context, key string, entry *Entry, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[key]; !ok && m.maxEntries > 0 && len(m.entries) >= m.maxEntries {
+		m.evict()
+	}
+	m.entries[key] = memoryEntry{Entry: entry, expires: time.Now().Add(ttl)}
+}
+
+// Invalidate implements Cache
+func (m *Memory) Invalidate(_ context.Context, types ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, e := range m.entries {
+		if containsAny(e.Types, types) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// evict removes the expired entries or the entry which expires next
+func (m *Memory) evict() {
+	now := time.Now()
+	next := ""
+	for key, e := range m.entries {
+		if now.After(e.expires) {
+			delete(m.entries, key)
+			continue
+		}
+		if next == "" || e.expires.Before(m.entries[next].expires) {
+			next = key
+		}
+	}
+	if len(m.entries) >= m.maxEntries && next != "" {
+		delete(m.entries, next)
+	}
+}
+
+func containsAny(list []string, values []string) bool {
+	for _, l := range list {
+		for _, v := range values {
+			if l == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/trustify/core/pkg/infrastructure/cache"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		act    func(c *cache.Memory)
+		assert func(t *testing.T, c *cache.Memory)
+	}{
+		{
+			name: "it should invalidate the entries containing a type",
+			act: func(c *cache.Memory) {
+				c.Set(ctx, "users", &cache.Entry{Types: []string{"User", "UserConnection"}}, time.Minute)
+				c.Set(ctx, "other", &cache.Entry{Types: []string{"Group"}}, time.Minute)
+				c.Invalidate(ctx, "User")
+			},
+			assert: func(t *testing.T, c *cache.Memory) {
+				_, ok := c.Get(ctx, "users")
+				assert.False(t, ok)
+				_, ok = c.Get(ctx, "other")
+				assert.True(t, ok)
+			},
+		},
+		{
+			name: "it should not return expired entries",
+			act: func(c *cache.Memory) {
+				c.Set(ctx, "users", &cache.Entry{}, -time.Second)
+			},
+			assert: func(t *testing.T, c *cache.Memory) {
+				_, ok := c.Get(ctx, "users")
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "it should evict the entry which expires next if the cache is full",
+			act: func(c *cache.Memory) {
+				c.Set(ctx, "short", &cache.Entry{}, time.Minute)
+				c.Set(ctx, "long", &cache.Entry{}, time.Hour)
+				c.Set(ctx, "new", &cache.Entry{}, time.Hour)
+			},
+			assert: func(t *testing.T, c *cache.Memory) {
+				_, ok := c.Get(ctx, "short")
+				assert.False(t, ok)
+				_, ok = c.Get(ctx, "long")
+				assert.True(t, ok)
+				_, ok = c.Get(ctx, "new")
+				assert.True(t, ok)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.NewMemory(2)
+			tt.act(c)
+			tt.assert(t, c)
+		})
+	}
+}
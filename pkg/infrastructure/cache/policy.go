@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Scope of a cache policy
+type Scope string
+
+// Scopes of the @cacheControl directive
+const (
+	ScopePublic  Scope = "PUBLIC"
+	ScopePrivate Scope = "PRIVATE"
+)
+
+type policyKey struct{}
+
+// Policy is the cache policy of a response computed from the @cacheControl hints of its fields.
+// It also records the types of the response, so that cached responses can be invalidated by type.
+type Policy struct {
+	mu     sync.Mutex
+	maxAge int
+	// restricted is set once a field restricted the maxAge
+	restricted bool
+	scope      Scope
+	noStore    bool
+	types      map[string]struct{}
+}
+
+// NewPolicy returns a policy which is public and does not allow caching until a field sets a maxAge
+func NewPolicy() *Policy {
+	return &Policy{scope: ScopePublic, types: map[string]struct{}{}}
+}
+
+// WithPolicy returns a context carrying the policy
+func WithPolicy(ctx context.Context, p *Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+// PolicyFromContext returns the policy of the context or nil
+func PolicyFromContext(ctx context.Context) *Policy {
+	p, _ := ctx.Value(policyKey{}).(*Policy)
+	return p
+}
+
+// RestrictMaxAge lowers the maxAge of the policy to maxAge
+func (p *Policy) RestrictMaxAge(maxAge int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.restricted || maxAge < p.maxAge {
+		p.maxAge = maxAge
+		p.restricted = true
+	}
+}
+
+// RestrictScope makes the policy private if scope is private
+func (p *Policy) RestrictScope(scope Scope) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if scope == ScopePrivate {
+		p.scope = ScopePrivate
+	}
+}
+
+// NoStore forbids caching, e.g. for mutations and responses with errors
+func (p *Policy) NoStore() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.noStore = true
+}
+
+// AddType records a type contained in the response
+func (p *Policy) AddType(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.types[name] = struct{}{}
+}
+
+// Types returns the sorted types contained in the response
+func (p *Policy) Types() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result := make([]string, 0, len(p.types))
+	for t := range p.types {
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// MaxAge returns the seconds the response may be cached or 0 if it must not be cached
+func (p *Policy) MaxAge() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.noStore || !p.restricted {
+		return 0
+	}
+	return p.maxAge
+}
+
+// Scope returns the scope of the policy
+func (p *Policy) Scope() Scope {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.scope
+}
+
+// Shared reports whether shared caches may store the response
+func (p *Policy) Shared() bool {
+	return p.MaxAge() > 0 && p.Scope() == ScopePublic
+}
+
+// Header returns the value of the Cache-Control header
+func (p *Policy) Header() string {
+	maxAge := p.MaxAge()
+	if maxAge <= 0 {
+		return "no-store"
+	}
+	if p.Scope() == ScopePrivate {
+		return fmt.Sprintf("max-age=%d, private", maxAge)
+	}
+	return fmt.Sprintf("max-age=%d, public", maxAge)
+}
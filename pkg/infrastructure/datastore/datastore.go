@@ -4,31 +4,54 @@ import (
 	"fmt"
 
 	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gitlab.com/trustify/core/config"
 	"gitlab.com/trustify/core/ent"
+	// registers the defaults, validators and hooks of the schema, e.g. the version hook
+	_ "gitlab.com/trustify/core/ent/runtime"
+	"gitlab.com/trustify/core/pkg/util/metrics"
 )
 
-// New returns data source name
+// New returns data source name.
+// The similarity threshold of searches is set for every connection, so that the <% operator of pg_trgm uses it.
 func New() string {
 	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s pg_trgm.word_similarity_threshold=%g",
 		config.C.Database.Host,
 		config.C.Database.Port,
 		config.C.Database.User,
 		config.C.Database.Password,
 		config.C.Database.Name,
 		config.C.Database.SSL,
+		config.C.Search.SimilarityThreshold,
 	)
 	return dsn
 }
 
-// NewClient returns an orm client
+// NewClient returns an orm client.
+// Every statement executed by the client is traced and profiled, slow statements are logged.
+// The statistics of the connection pool are exposed as metrics.
+// Statements are logged if SQL logging is enabled in the config.
 func NewClient() (*ent.Client, error) {
-	var entOpt []ent.Option
-	entOpt = append(entOpt, ent.Debug())
-
 	dsn := New()
 
-	return ent.Open(dialect.Postgres, dsn, entOpt...)
+	drv, err := entsql.Open(dialect.Postgres, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := metrics.Register(collectors.NewDBStatsCollector(drv.DB(), config.C.Database.Name)); err != nil {
+		return nil, err
+	}
+
+	interceptors := []interceptor{traceStatement, profileStatement}
+	if config.C.Log.SQL {
+		interceptors = append(interceptors, logStatement)
+	}
+
+	var entOpt []ent.Option
+	entOpt = append(entOpt, ent.Driver(newDriver(drv, interceptors...)))
+
+	return ent.NewClient(entOpt...), nil
 }
@@ -0,0 +1,84 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"entgo.io/ent/dialect"
+)
+
+// interceptor wraps the execution of a statement, next executes the statement
+type interceptor func(ctx context.Context, query string, args interface{}, next func(ctx context.Context) error) error
+
+// interceptDriver runs every statement executed by the underlying driver through the interceptor
+type interceptDriver struct {
+	dialect.Driver
+	intercept interceptor
+}
+
+// newDriver wraps the driver with the interceptors, the first interceptor is the outermost
+func newDriver(drv dialect.Driver, interceptors ...interceptor) dialect.Driver {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		drv = &interceptDriver{Driver: drv, intercept: interceptors[i]}
+	}
+	return drv
+}
+
+// Exec calls the underlying driver Exec method through the interceptor.
+func (d *interceptDriver) Exec(ctx context.Context, query string, args, v interface{}) error {
+	return d.intercept(ctx, query, args, func(ctx context.Context) error {
+		return d.Driver.Exec(ctx, query, args, v)
+	})
+}
+
+// Query calls the underlying driver Query method through the interceptor.
+func (d *interceptDriver) Query(ctx context.Context, query string, args, v interface{}) error {
+	return d.intercept(ctx, query, args, func(ctx context.Context) error {
+		return d.Driver.Query(ctx, query, args, v)
+	})
+}
+
+// Tx calls the underlying driver Tx method and intercepts the statements of the transaction.
+func (d *interceptDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	tx, err := d.Driver.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &interceptTx{Tx: tx, intercept: d.intercept}, nil
+}
+
+// BeginTx calls the underlying driver BeginTx method and intercepts the statements of the transaction.
+func (d *interceptDriver) BeginTx(ctx context.Context, opts *sql.TxOptions) (dialect.Tx, error) {
+	drv, ok := d.Driver.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (dialect.Tx, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Driver.BeginTx is not supported")
+	}
+	tx, err := drv.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &interceptTx{Tx: tx, intercept: d.intercept}, nil
+}
+
+// interceptTx runs every statement executed in the underlying transaction through the interceptor
+type interceptTx struct {
+	dialect.Tx
+	intercept interceptor
+}
+
+// Exec calls the underlying transaction Exec method through the interceptor.
+func (t *interceptTx) Exec(ctx context.Context, query string, args, v interface{}) error {
+	return t.intercept(ctx, query, args, func(ctx context.Context) error {
+		return t.Tx.Exec(ctx, query, args, v)
+	})
+}
+
+// Query calls the underlying transaction Query method through the interceptor.
+func (t *interceptTx) Query(ctx context.Context, query string, args, v interface{}) error {
+	return t.intercept(ctx, query, args, func(ctx context.Context) error {
+		return t.Tx.Query(ctx, query, args, v)
+	})
+}
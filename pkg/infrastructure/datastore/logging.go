@@ -0,0 +1,19 @@
+package datastore
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// logStatement logs the query with the number of bound arguments instead of their values,
+// they may contain secrets like passwords.
+func logStatement(ctx context.Context, query string, args interface{}, next func(ctx context.Context) error) error {
+	n := 0
+	if rv := reflect.ValueOf(args); rv.Kind() == reflect.Slice {
+		n = rv.Len()
+	}
+	slog.DebugContext(ctx, "sql", slog.String("query", query), slog.Int("args", n))
+
+	return next(ctx)
+}
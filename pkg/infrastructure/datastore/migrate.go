@@ -0,0 +1,63 @@
+package datastore
+
+import (
+	"context"
+
+	"ariga.io/atlas/sql/migrate"
+	atlas "ariga.io/atlas/sql/schema"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql/schema"
+	entschema "gitlab.com/trustify/core/ent/schema"
+	"gitlab.com/trustify/core/ent/user"
+)
+
+// MigrateOptions extends the migration of the ent schema by the objects ent cannot describe,
+// i.e. the full-text search column of users and the search indexes.
+// They are hidden from the diff, so ent does not drop them, and created after the ent schema in the same transaction.
+func MigrateOptions() []schema.MigrateOption {
+	return []schema.MigrateOption{
+		schema.WithDiffHook(hideUserSearch),
+		schema.WithApplyHook(applyUserSearch),
+	}
+}
+
+// hideUserSearch removes the search column and the search indexes from the current schema before it is diffed
+func hideUserSearch(next schema.Differ) schema.Differ {
+	return schema.DiffFunc(func(current, desired *atlas.Schema) ([]atlas.Change, error) {
+		if t, ok := current.Table(user.Table); ok {
+			columns := t.Columns[:0]
+			for _, c := range t.Columns {
+				if c.Name != entschema.UserSearchColumn {
+					columns = append(columns, c)
+				}
+			}
+			t.Columns = columns
+
+			indexes := t.Indexes[:0]
+			for _, idx := range t.Indexes {
+				if idx.Name != entschema.UserSearchIndex && idx.Name != entschema.UserSearchTrigramIndex {
+					indexes = append(indexes, idx)
+				}
+			}
+			t.Indexes = indexes
+		}
+
+		return next.Diff(current, desired)
+	})
+}
+
+// applyUserSearch creates the search column and the search indexes after the changes of the ent schema
+func applyUserSearch(next schema.Applier) schema.Applier {
+	return schema.ApplyFunc(func(ctx context.Context, conn dialect.ExecQuerier, plan *migrate.Plan) error {
+		if err := next.Apply(ctx, conn, plan); err != nil {
+			return err
+		}
+		for _, stmt := range entschema.UserSearchStatements {
+			if err := conn.Exec(ctx, stmt, []interface{}{}, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
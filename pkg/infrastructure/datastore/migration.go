@@ -0,0 +1,498 @@
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	atlasmigrate "ariga.io/atlas/sql/migrate"
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/schema"
+	entmigrate "gitlab.com/trustify/core/ent/migrate"
+	entschema "gitlab.com/trustify/core/ent/schema"
+	"gitlab.com/trustify/core/ent/user"
+)
+
+// MigrationDir is the directory of the versioned migrations, relative to the root of the repository
+const MigrationDir = "migrations"
+
+const (
+	// migrationTable records the applied migrations with their checksum
+	migrationTable = "schema_migrations"
+	// migrationLockID is the key of the advisory lock held while migrations are applied or reverted
+	migrationLockID = 7231308
+	// migrationVersionFormat is the layout of the versions, the UTC time the migration was generated
+	migrationVersionFormat = "20060102150405"
+)
+
+var (
+	migrationFileRegexp = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(up|down)\.sql$`)
+	migrationNameRegexp = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration is a versioned migration read from the files <version>_<name>.up.sql and <version>_<name>.down.sql
+type Migration struct {
+	Version string
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of both files, it must not change once the migration is applied
+	Checksum string
+}
+
+// File returns the name of the migration files without the direction and the extension
+func (m *Migration) File() string {
+	return m.Version + "_" + m.Name
+}
+
+// MigrationStatus is the state of a migration in the database
+type MigrationStatus struct {
+	Version string
+	Name    string
+	// Migration is nil if the migration was applied but its files are missing
+	Migration *Migration
+	// AppliedAt is nil if the migration is pending
+	AppliedAt *time.Time
+	// AppliedChecksum is the checksum of the files when the migration was applied
+	AppliedChecksum string
+}
+
+// Pending reports whether the migration is not applied
+func (s *MigrationStatus) Pending() bool {
+	return s.AppliedAt == nil
+}
+
+// Missing reports whether the migration is applied but its files are missing
+func (s *MigrationStatus) Missing() bool {
+	return s.Migration == nil
+}
+
+// Modified reports whether the files of an applied migration were changed
+func (s *MigrationStatus) Modified() bool {
+	return !s.Pending() && !s.Missing() && s.Migration.Checksum != s.AppliedChecksum
+}
+
+// ReadMigrations returns the migrations of dir ordered by version
+func ReadMigrations(dir string) ([]*Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[string]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFileRegexp.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s must be named <version>_<name>.up.sql or <version>_<name>.down.sql", e.Name())
+		}
+		version, name, direction := match[1], match[2], match[3]
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration files of version %s have different names %s and %s", version, m.Name, name)
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if direction == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s has no up file", m.File())
+		}
+		h := sha256.New()
+		h.Write([]byte(m.Up))
+		h.Write([]byte{0})
+		h.Write([]byte(m.Down))
+		m.Checksum = hex.EncodeToString(h.Sum(nil))
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// ValidateMigrations returns an error if an applied migration is missing or was modified,
+// or if a pending migration is older than an applied one, e.g. after merging branches.
+func ValidateMigrations(statuses []*MigrationStatus) error {
+	var errs []error
+	var lastApplied string
+	for _, s := range statuses {
+		switch {
+		case s.Pending():
+			continue
+		case s.Missing():
+			errs = append(errs, fmt.Errorf("applied migration %s_%s is missing", s.Version, s.Name))
+		case s.Modified():
+			errs = append(errs, fmt.Errorf("migration %s was modified after it was applied", s.Migration.File()))
+		}
+		lastApplied = s.Version
+	}
+	for _, s := range statuses {
+		if s.Pending() && s.Version < lastApplied {
+			errs = append(errs, fmt.Errorf("pending migration %s is older than the last applied migration %s", s.Migration.File(), lastApplied))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Migrator applies and reverts the versioned migrations of a directory and records them in the database
+type Migrator struct {
+	db  *sql.DB
+	dir string
+}
+
+// NewMigrator returns a migrator of the migrations in dir
+func NewMigrator(db *sql.DB, dir string) *Migrator {
+	return &Migrator{db: db, dir: dir}
+}
+
+// Status returns the state of every migration of the directory and of every applied migration
+func (m *Migrator) Status(ctx context.Context) ([]*MigrationStatus, error) {
+	return m.status(ctx, m.db)
+}
+
+// Up applies at most n pending migrations, all if n is 0, and returns them.
+// Every migration is applied in its own transaction.
+func (m *Migrator) Up(ctx context.Context, n int) ([]*Migration, error) {
+	var applied []*Migration
+	err := m.locked(ctx, func(conn *sql.Conn, statuses []*MigrationStatus) error {
+		for _, s := range statuses {
+			if !s.Pending() {
+				continue
+			}
+			if n > 0 && len(applied) == n {
+				break
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, s.Migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `INSERT INTO `+migrationTable+` (version, name, checksum) VALUES ($1, $2, $3)`,
+					s.Version, s.Name, s.Migration.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s: %w", s.Migration.File(), err)
+			}
+			applied = append(applied, s.Migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last n applied migrations and returns them
+func (m *Migrator) Down(ctx context.Context, n int) ([]*Migration, error) {
+	var reverted []*Migration
+	err := m.locked(ctx, func(conn *sql.Conn, statuses []*MigrationStatus) error {
+		for i := len(statuses) - 1; i >= 0 && len(reverted) < n; i-- {
+			s := statuses[i]
+			if s.Pending() {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if strings.TrimSpace(s.Migration.Down) != "" {
+					if _, err := tx.ExecContext(ctx, s.Migration.Down); err != nil {
+						return err
+					}
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM `+migrationTable+` WHERE version = $1`, s.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s: %w", s.Migration.File(), err)
+			}
+			reverted = append(reverted, s.Migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Diff writes a migration named name from the state of the migrations of the directory to the ent schema.
+// The migrations are replayed in a temporary schema of the database, which is dropped afterwards.
+// It returns the names of the written files, none if the migrations are up to date.
+func (m *Migrator) Diff(ctx context.Context, name string) ([]string, error) {
+	if !migrationNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("migration name %q must only contain lowercase letters, digits and underscores", name)
+	}
+	migrations, err := ReadMigrations(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	devSchema := fmt.Sprintf("migration_diff_%d", time.Now().UnixNano())
+	if _, err := m.db.ExecContext(ctx, `CREATE SCHEMA `+devSchema); err != nil {
+		return nil, err
+	}
+	defer m.db.ExecContext(context.Background(), `DROP SCHEMA `+devSchema+` CASCADE`)
+
+	dev, err := openSchema(ctx, m.db, devSchema)
+	if err != nil {
+		return nil, err
+	}
+	defer dev.Close()
+	for _, mig := range migrations {
+		if _, err := dev.ExecContext(ctx, mig.Up); err != nil {
+			return nil, fmt.Errorf("replaying migration %s: %w", mig.File(), err)
+		}
+	}
+
+	// the search column is created by statements ent does not know, see MigrateOptions
+	var hasSearch bool
+	err = dev.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2)`,
+		user.Table, entschema.UserSearchColumn).Scan(&hasSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := atlasmigrate.NewLocalDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+	f := &migrationFormatter{
+		version:   time.Now().UTC().Format(migrationVersionFormat),
+		name:      name,
+		devSchema: devSchema,
+		addSearch: !hasSearch,
+	}
+	opts := append([]schema.MigrateOption{
+		schema.WithDir(dir),
+		schema.WithFormatter(f),
+		// dropped columns and indexes are written to the file, where they are reviewed
+		schema.WithDropColumn(true),
+		schema.WithDropIndex(true),
+	}, MigrateOptions()...)
+	mig, err := schema.NewMigrate(entsql.OpenDB(dialect.Postgres, dev), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := mig.Diff(ctx, entmigrate.Tables...); err != nil {
+		return nil, err
+	}
+
+	return f.files, nil
+}
+
+// migrationExtensions are the extensions created by the migrations
+var migrationExtensions = []string{entschema.UserSearchExtension}
+
+// openSchema returns a connection to the schema of the database, which also resolves the objects of public.
+// The extensions of the migrations are created in public first: an extension which is missing would otherwise
+// be created in the schema by the migrations and dropped with it, and an existing one would not be found.
+func openSchema(ctx context.Context, db *sql.DB, schema string) (*sql.DB, error) {
+	for _, ext := range migrationExtensions {
+		if _, err := db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS `+ext+` WITH SCHEMA public`); err != nil {
+			return nil, err
+		}
+	}
+	return sql.Open(dialect.Postgres, New()+" search_path="+schema+",public")
+}
+
+// status returns the state of the migrations, the migrations table is created if it does not exist
+func (m *Migrator) status(ctx context.Context, conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}) ([]*MigrationStatus, error) {
+	migrations, err := ReadMigrations(m.dir)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+migrationTable+` (
+		version character varying NOT NULL PRIMARY KEY,
+		name character varying NOT NULL,
+		checksum character varying NOT NULL,
+		applied_at timestamp with time zone NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[string]*MigrationStatus{}
+	for _, mig := range migrations {
+		byVersion[mig.Version] = &MigrationStatus{Version: mig.Version, Name: mig.Name, Migration: mig}
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM `+migrationTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version, name, checksum string
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &name, &checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		s, ok := byVersion[version]
+		if !ok {
+			s = &MigrationStatus{Version: version, Name: name}
+			byVersion[version] = s
+		}
+		s.AppliedAt = &appliedAt
+		s.AppliedChecksum = checksum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(byVersion))
+	for _, s := range byVersion {
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// locked runs fn with the validated status of the migrations while holding the migration lock,
+// so that concurrent deployments do not apply the same migrations
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, statuses []*MigrationStatus) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	statuses, err := m.status(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := ValidateMigrations(statuses); err != nil {
+		return err
+	}
+
+	return fn(conn, statuses)
+}
+
+// inTx runs fn in a transaction of conn, which is committed if fn succeeds
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrationFormatter formats the plan of Migrator.Diff as the up and down files of a versioned migration
+type migrationFormatter struct {
+	version string
+	name    string
+	// devSchema is the temporary schema the plan was computed in, whose name is removed from the statements
+	devSchema string
+	// addSearch adds the search column of users, which is not part of the plan, see MigrateOptions
+	addSearch bool
+	// files are the names of the written files
+	files []string
+}
+
+var _ atlasmigrate.Formatter = (*migrationFormatter)(nil)
+
+// Format implements migrate.Formatter. It returns no files if the plan has no changes.
+func (f *migrationFormatter) Format(plan *atlasmigrate.Plan) ([]atlasmigrate.File, error) {
+	if len(plan.Changes) == 0 && !f.addSearch {
+		return nil, nil
+	}
+
+	var up, down strings.Builder
+	if f.addSearch {
+		for _, stmt := range entschema.UserSearchDropStatements {
+			writeStatement(&down, "", stmt)
+		}
+	}
+	for _, c := range plan.Changes {
+		writeStatement(&up, c.Comment, f.unqualified(c.Cmd))
+	}
+	if f.addSearch {
+		for _, stmt := range entschema.UserSearchStatements {
+			writeStatement(&up, "", stmt)
+		}
+	}
+	for i := len(plan.Changes) - 1; i >= 0; i-- {
+		c := plan.Changes[i]
+		if c.Reverse == "" {
+			fmt.Fprintf(&down, "-- %s can not be reverted\n", c.Comment)
+			continue
+		}
+		writeStatement(&down, "reverse: "+c.Comment, f.unqualified(c.Reverse))
+	}
+
+	file := f.version + "_" + f.name
+	files := []atlasmigrate.File{
+		&migrationFile{Buffer: bytes.NewBufferString(up.String()), name: file + ".up.sql"},
+		&migrationFile{Buffer: bytes.NewBufferString(down.String()), name: file + ".down.sql"},
+	}
+	for _, file := range files {
+		f.files = append(f.files, file.Name())
+	}
+
+	return files, nil
+}
+
+// unqualified removes the temporary schema from the names of a statement
+func (f *migrationFormatter) unqualified(stmt string) string {
+	return strings.ReplaceAll(stmt, `"`+f.devSchema+`".`, "")
+}
+
+// writeStatement writes a statement terminated by a semicolon, preceded by its comment if any
+func writeStatement(b *strings.Builder, comment string, stmt string) {
+	if comment != "" {
+		fmt.Fprintf(b, "-- %s\n", comment)
+	}
+	b.WriteString(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+	b.WriteString(";\n")
+}
+
+// migrationFile is a formatted migration file
+type migrationFile struct {
+	*bytes.Buffer
+	name string
+}
+
+// Name implements migrate.File
+func (f *migrationFile) Name() string {
+	return f.name
+}
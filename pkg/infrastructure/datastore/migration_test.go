@@ -0,0 +1,326 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	atlasmigrate "ariga.io/atlas/sql/migrate"
+	"entgo.io/ent/dialect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/trustify/core/config"
+	entschema "gitlab.com/trustify/core/ent/schema"
+	"gitlab.com/trustify/core/pkg/util/environment"
+)
+
+func TestReadMigrations(t *testing.T) {
+	type args struct {
+		files map[string]string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "it should read the migrations ordered by version",
+			args: args{files: map[string]string{
+				"20261020000000_add_phone.up.sql":   "ALTER TABLE users ADD COLUMN phone text;",
+				"20261020000000_add_phone.down.sql": "ALTER TABLE users DROP COLUMN phone;",
+				"20261019000000_init.up.sql":        "CREATE TABLE users (id text);",
+				"20261019000000_init.down.sql":      "DROP TABLE users;",
+				"README.md":                         "ignored",
+			}},
+			want: []string{"20261019000000_init", "20261020000000_add_phone"},
+		},
+		{
+			name: "it should fail on a migration without up file",
+			args: args{files: map[string]string{
+				"20261019000000_init.down.sql": "DROP TABLE users;",
+			}},
+			wantErr: "migration 20261019000000_init has no up file",
+		},
+		{
+			name: "it should fail on a misnamed file",
+			args: args{files: map[string]string{
+				"init.sql": "CREATE TABLE users (id text);",
+			}},
+			wantErr: "migration file init.sql must be named <version>_<name>.up.sql or <version>_<name>.down.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.args.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+			}
+
+			got, err := ReadMigrations(dir)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var files []string
+			for _, m := range got {
+				files = append(files, m.File())
+				assert.Len(t, m.Checksum, 64)
+			}
+			assert.Equal(t, tt.want, files)
+		})
+	}
+
+	t.Run("it should read the committed migrations", func(t *testing.T) {
+		got, err := ReadMigrations(filepath.Join("..", "..", "..", MigrationDir))
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, got)
+	})
+}
+
+func TestValidateMigrations(t *testing.T) {
+	appliedAt := time.Now()
+	migration := func(version string) *Migration {
+		return &Migration{Version: version, Name: "init", Checksum: "sum"}
+	}
+
+	tests := []struct {
+		name     string
+		statuses []*MigrationStatus
+		wantErr  string
+	}{
+		{
+			name: "it should accept applied and newer pending migrations",
+			statuses: []*MigrationStatus{
+				{Version: "1", Name: "init", Migration: migration("1"), AppliedAt: &appliedAt, AppliedChecksum: "sum"},
+				{Version: "2", Name: "init", Migration: migration("2")},
+			},
+		},
+		{
+			name: "it should fail on a modified migration",
+			statuses: []*MigrationStatus{
+				{Version: "1", Name: "init", Migration: migration("1"), AppliedAt: &appliedAt, AppliedChecksum: "other"},
+			},
+			wantErr: "migration 1_init was modified after it was applied",
+		},
+		{
+			name: "it should fail on a missing migration",
+			statuses: []*MigrationStatus{
+				{Version: "1", Name: "init", AppliedAt: &appliedAt, AppliedChecksum: "sum"},
+			},
+			wantErr: "applied migration 1_init is missing",
+		},
+		{
+			name: "it should fail on a pending migration older than an applied one",
+			statuses: []*MigrationStatus{
+				{Version: "1", Name: "init", Migration: migration("1")},
+				{Version: "2", Name: "init", Migration: migration("2"), AppliedAt: &appliedAt, AppliedChecksum: "sum"},
+			},
+			wantErr: "pending migration 1_init is older than the last applied migration 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMigrations(tt.statuses)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMigrationFormatter_Format(t *testing.T) {
+	type args struct {
+		addSearch bool
+		changes   []*atlasmigrate.Change
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantUp   string
+		wantDown string
+	}{
+		{
+			name: "it should write the changes and their reverse without the temporary schema",
+			args: args{changes: []*atlasmigrate.Change{
+				{Cmd: `CREATE TABLE "dev"."phones" ("id" character varying NOT NULL)`, Reverse: `DROP TABLE "dev"."phones"`, Comment: `create "phones" table`},
+				{Cmd: `ALTER TABLE "users" DROP COLUMN "avatar"`, Comment: `modify "users" table`},
+			}},
+			wantUp: "-- create \"phones\" table\nCREATE TABLE \"phones\" (\"id\" character varying NOT NULL);\n" +
+				"-- modify \"users\" table\nALTER TABLE \"users\" DROP COLUMN \"avatar\";\n",
+			wantDown: "-- modify \"users\" table can not be reverted\n" +
+				"-- reverse: create \"phones\" table\nDROP TABLE \"phones\";\n",
+		},
+		{
+			name:     "it should add the search column if it is missing",
+			args:     args{addSearch: true},
+			wantUp:   strings.Join(entschema.UserSearchStatements, ";\n") + ";\n",
+			wantDown: strings.Join(entschema.UserSearchDropStatements, ";\n") + ";\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &migrationFormatter{version: "20261019000000", name: "test", devSchema: "dev", addSearch: tt.args.addSearch}
+
+			got, err := f.Format(&atlasmigrate.Plan{Changes: tt.args.changes})
+
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+			assert.Equal(t, []string{"20261019000000_test.up.sql", "20261019000000_test.down.sql"}, f.files)
+			up, err := io.ReadAll(got[0])
+			require.NoError(t, err)
+			down, err := io.ReadAll(got[1])
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUp, string(up))
+			assert.Equal(t, tt.wantDown, string(down))
+		})
+	}
+
+	t.Run("it should write no files without changes", func(t *testing.T) {
+		f := &migrationFormatter{version: "20261019000000", name: "test", devSchema: "dev"}
+
+		got, err := f.Format(&atlasmigrate.Plan{})
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		assert.Empty(t, f.files)
+	})
+}
+
+// openTestSchema returns a connection to a new schema of the test database, which is dropped after the test.
+// The extensions of the migrations are installed in public like in the databases the migrations run against.
+func openTestSchema(t *testing.T) *sql.DB {
+	t.Helper()
+	config.ReadConfig(config.ReadConfigOption{AppEnv: environment.Test})
+
+	db, err := sql.Open(dialect.Postgres, New())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	name := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	_, err = db.Exec(`CREATE SCHEMA ` + name)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Exec(`DROP SCHEMA ` + name + ` CASCADE`) })
+
+	conn, err := openSchema(context.Background(), db, name)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// copyMigrations copies the committed migrations to a new directory, so that Diff does not write to the repository
+func copyMigrations(t *testing.T) string {
+	t.Helper()
+	src := filepath.Join("..", "..", "..", MigrationDir)
+	entries, err := os.ReadDir(src)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(src, e.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, e.Name()), b, 0644))
+	}
+	return dir
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	db := openTestSchema(t)
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"20261019000000_a.up.sql":   "CREATE TABLE a (id int);",
+		"20261019000000_a.down.sql": "DROP TABLE a;",
+		"20261019000001_b.up.sql":   "CREATE TABLE b (id int);",
+		"20261019000001_b.down.sql": "DROP TABLE b;",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	m := NewMigrator(db, dir)
+	ctx := context.Background()
+
+	files := func(migrations []*Migration) []string {
+		var result []string
+		for _, mig := range migrations {
+			result = append(result, mig.File())
+		}
+		return result
+	}
+	tableExists := func(name string) bool {
+		var exists bool
+		err := db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists)
+		require.NoError(t, err)
+		return exists
+	}
+
+	t.Run("it should apply at most n pending migrations", func(t *testing.T) {
+		got, err := m.Up(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20261019000000_a"}, files(got))
+		assert.True(t, tableExists("a"))
+		assert.False(t, tableExists("b"))
+	})
+
+	t.Run("it should apply the remaining pending migrations", func(t *testing.T) {
+		got, err := m.Up(ctx, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20261019000001_b"}, files(got))
+		statuses, err := m.Status(ctx)
+		require.NoError(t, err)
+		for _, s := range statuses {
+			assert.False(t, s.Pending(), s.Version)
+		}
+	})
+
+	t.Run("it should revert the last applied migrations", func(t *testing.T) {
+		got, err := m.Down(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20261019000001_b"}, files(got))
+		assert.True(t, tableExists("a"))
+		assert.False(t, tableExists("b"))
+	})
+
+	t.Run("it should fail if an applied migration was modified", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "20261019000000_a.up.sql"), []byte("CREATE TABLE c (id int);"), 0644))
+
+		_, err := m.Up(ctx, 0)
+
+		assert.Error(t, err)
+		assert.False(t, tableExists("b"))
+	})
+}
+
+func TestMigrator_Diff(t *testing.T) {
+	db := openTestSchema(t)
+	m := NewMigrator(db, copyMigrations(t))
+	ctx := context.Background()
+
+	t.Run("it should apply the committed migrations", func(t *testing.T) {
+		_, err := m.Up(ctx, 0)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("it should report no changes between the committed migrations and the ent schema", func(t *testing.T) {
+		got, err := m.Diff(ctx, "test")
+
+		require.NoError(t, err)
+		assert.Empty(t, got, "the ent schema has changes without a migration, run make migrate_diff")
+	})
+}
@@ -0,0 +1,127 @@
+package datastore
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"gitlab.com/trustify/core/config"
+)
+
+// maxShapePaths limits the GraphQL paths recorded per query shape
+const maxShapePaths = 5
+
+type queryStatsKey struct{}
+
+// QueryStats records the statements executed during a request
+type QueryStats struct {
+	mu       sync.Mutex
+	count    int
+	duration time.Duration
+	shapes   map[string]*QueryShape
+}
+
+// QueryShape is a statement which was executed repeatedly.
+// Bound arguments are not part of the statement, so each shape is one kind of query.
+type QueryShape struct {
+	Query string   `json:"query"`
+	Count int      `json:"count"`
+	Paths []string `json:"paths"`
+}
+
+// QueryStatsSummary is the summary of the statements of a request
+type QueryStatsSummary struct {
+	Count int `json:"count"`
+	// Duration is the total time spent in the database, e.g. 12.5ms
+	Duration string `json:"duration"`
+	// NPlusOne are the shapes which were executed at least config.C.Database.NPlusOneThreshold times
+	NPlusOne []QueryShape `json:"nPlusOne,omitempty"`
+}
+
+// WithQueryStats returns a context in which the statements executed by clients of NewClient are recorded
+func WithQueryStats(ctx context.Context) (context.Context, *QueryStats) {
+	s := &QueryStats{shapes: map[string]*QueryShape{}}
+	return context.WithValue(ctx, queryStatsKey{}, s), s
+}
+
+func queryStatsFromContext(ctx context.Context) *QueryStats {
+	s, _ := ctx.Value(queryStatsKey{}).(*QueryStats)
+	return s
+}
+
+// Summary returns the number and duration of the statements and the likely N+1 queries
+func (s *QueryStats) Summary() QueryStatsSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := QueryStatsSummary{Count: s.count, Duration: s.duration.String()}
+	threshold := config.C.Database.NPlusOneThreshold
+	for _, shape := range s.shapes {
+		if threshold > 0 && shape.Count >= threshold {
+			summary.NPlusOne = append(summary.NPlusOne, *shape)
+		}
+	}
+	sort.Slice(summary.NPlusOne, func(i, j int) bool {
+		if summary.NPlusOne[i].Count != summary.NPlusOne[j].Count {
+			return summary.NPlusOne[i].Count > summary.NPlusOne[j].Count
+		}
+		return summary.NPlusOne[i].Query < summary.NPlusOne[j].Query
+	})
+
+	return summary
+}
+
+func (s *QueryStats) record(query string, path string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count++
+	s.duration += d
+
+	shape, ok := s.shapes[query]
+	if !ok {
+		shape = &QueryShape{Query: query}
+		s.shapes[query] = shape
+	}
+	shape.Count++
+	if path != "" && len(shape.Paths) < maxShapePaths && !contains(shape.Paths, path) {
+		shape.Paths = append(shape.Paths, path)
+	}
+}
+
+// profileStatement records the statement in the QueryStats of the context
+// and logs statements slower than config.C.Database.SlowQueryThreshold.
+func profileStatement(ctx context.Context, query string, _ interface{}, next func(ctx context.Context) error) error {
+	start := time.Now()
+	err := next(ctx)
+	d := time.Since(start)
+
+	path := ""
+	if fc := graphql.GetFieldContext(ctx); fc != nil {
+		path = fc.Path().String()
+	}
+	if s := queryStatsFromContext(ctx); s != nil {
+		s.record(query, path, d)
+	}
+	if threshold := config.C.Database.SlowQueryThreshold; threshold > 0 && d >= threshold {
+		slog.WarnContext(ctx, "slow query",
+			slog.String("query", query),
+			slog.Duration("duration", d),
+			slog.String("path", path),
+		)
+	}
+
+	return err
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gitlab.com/trustify/core/config"
+)
+
+func TestQueryStats_Summary(t *testing.T) {
+	threshold := config.C.Database.NPlusOneThreshold
+	config.C.Database.NPlusOneThreshold = 3
+	t.Cleanup(func() { config.C.Database.NPlusOneThreshold = threshold })
+
+	type args struct {
+		queries []string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  int
+		nPlus []QueryShape
+	}{
+		{
+			name: "it should report repeated query shapes as N+1",
+			args: args{queries: []string{
+				`SELECT * FROM "users"`,
+				`SELECT * FROM "groups" WHERE "user_id" = $1`,
+				`SELECT * FROM "groups" WHERE "user_id" = $1`,
+				`SELECT * FROM "groups" WHERE "user_id" = $1`,
+			}},
+			want: 4,
+			nPlus: []QueryShape{
+				{Query: `SELECT * FROM "groups" WHERE "user_id" = $1`, Count: 3, Paths: []string{"users"}},
+			},
+		},
+		{
+			name: "it should not report queries below the threshold",
+			args: args{queries: []string{
+				`SELECT * FROM "users" WHERE "id" = $1`,
+				`SELECT * FROM "users" WHERE "id" = $1`,
+			}},
+			want:  2,
+			nPlus: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stats := WithQueryStats(context.Background())
+			ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{
+				Field: graphql.CollectedField{Field: &ast.Field{Alias: "users"}},
+			})
+
+			for _, q := range tt.args.queries {
+				err := profileStatement(ctx, q, nil, func(context.Context) error { return nil })
+				assert.NoError(t, err)
+			}
+
+			got := stats.Summary()
+
+			assert.Equal(t, tt.want, got.Count)
+			assert.Equal(t, tt.nPlus, got.NPlusOne)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package datastore
+
+import (
+	"context"
+	"strings"
+
+	"gitlab.com/trustify/core/pkg/infrastructure/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceStatement runs the statement in a span named after the operation of the query.
+// Arguments are not recorded, they may contain secrets like passwords.
+func traceStatement(ctx context.Context, query string, _ interface{}, next func(ctx context.Context) error) error {
+	operation := strings.ToUpper(strings.SplitN(strings.TrimSpace(query), " ", 2)[0])
+	ctx, span := tracing.Tracer().Start(ctx, "sql "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(operation),
+			semconv.DBStatementKey.String(query),
+		),
+	)
+	defer span.End()
+
+	err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,72 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceStatement(t *testing.T) {
+	type args struct {
+		query string
+		err   error
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantName   string
+		wantStatus codes.Code
+	}{
+		{
+			name:       "it should trace a statement as child of the span in the context",
+			args:       args{query: ` select "id" from "users" where "email" = $1`},
+			wantName:   "sql SELECT",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "it should record the error of a statement",
+			args:       args{query: `UPDATE "users" SET "version" = 2`, err: errors.New("connection reset")},
+			wantName:   "sql UPDATE",
+			wantStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			global := otel.GetTracerProvider()
+			defer otel.SetTracerProvider(global)
+			sr := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+			ctx, parent := otel.Tracer("test").Start(context.Background(), "Query.user")
+
+			err := traceStatement(ctx, tt.args.query, []interface{}{"secret"}, func(context.Context) error {
+				return tt.args.err
+			})
+			parent.End()
+
+			assert.Equal(t, tt.args.err, err)
+			spans := sr.Ended()
+			require.Len(t, spans, 2)
+			got := spans[0]
+			assert.Equal(t, tt.wantName, got.Name())
+			assert.Equal(t, trace.SpanKindClient, got.SpanKind())
+			assert.Equal(t, parent.SpanContext().SpanID(), got.Parent().SpanID())
+			assert.ElementsMatch(t, []attribute.KeyValue{
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationKey.String(tt.wantName[len("sql "):]),
+				semconv.DBStatementKey.String(tt.args.query),
+			}, got.Attributes())
+			assert.Equal(t, tt.wantStatus, got.Status().Code)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/infrastructure/idempotency"
+)
+
+// batchPOST is a transport executing a JSON array of operations sent in one POST request.
+// The operations are executed in order and the response is the array of their results.
+// It must be added before transport.POST, which handles single operations.
+type batchPOST struct{}
+
+var _ graphql.Transport = batchPOST{}
+
+// Supports implements graphql.Transport.
+// The body is peeked to tell a batch from a single operation.
+func (batchPOST) Supports(r *http.Request) bool {
+	if r.Method != http.MethodPost || r.Header.Get("Upgrade") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return false
+	}
+
+	br := bufio.NewReader(r.Body)
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{br, r.Body}
+
+	for i := 1; ; i++ {
+		b, err := br.Peek(i)
+		if err != nil {
+			return false
+		}
+		switch b[i-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// Do implements graphql.Transport
+func (batchPOST) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var batch []*graphql.RawParams
+	start := graphql.Now()
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		err = model.NewValidationError(err, "json body could not be decoded")
+		writeJSON(w, dispatchError(ctx, exec, gqlerror.List{gqlerror.WrapPath(nil, err)}))
+		return
+	}
+	if idempotency.KeyFromContext(ctx) != "" {
+		// the key identifies a single mutation, the operations of a batch would reuse it
+		err := model.NewValidationError(fmt.Errorf("%s header with %d operations", idempotency.Header, len(batch)), "idempotency key is not supported for batches")
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, dispatchError(ctx, exec, gqlerror.List{gqlerror.WrapPath(nil, err)}))
+		return
+	}
+	if max := config.C.GraphQL.MaxBatchSize; len(batch) > max {
+		err := model.NewValidationError(fmt.Errorf("%d operations exceed the limit of %d", len(batch), max), "too many operations in batch")
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, dispatchError(ctx, exec, gqlerror.List{gqlerror.WrapPath(nil, err)}))
+		return
+	}
+	end := graphql.Now()
+
+	results := make([]*graphql.Response, len(batch))
+	for i, params := range batch {
+		if params == nil {
+			err := model.NewValidationError(fmt.Errorf("operation %d is null", i), "operation must be an object")
+			results[i] = dispatchError(ctx, exec, gqlerror.List{gqlerror.WrapPath(nil, err)})
+			continue
+		}
+		params.ReadTime = graphql.TraceTiming{Start: start, End: end}
+
+		rc, errs := exec.CreateOperationContext(ctx, params)
+		if errs != nil {
+			results[i] = exec.DispatchError(graphql.WithOperationContext(ctx, rc), errs)
+			continue
+		}
+		responses, opCtx := exec.DispatchOperation(ctx, rc)
+		results[i] = responses(opCtx)
+	}
+
+	writeJSON(w, results)
+}
+
+// dispatchError responds with errors raised before an operation could be created.
+// The response interceptors, e.g. entgql.Transactioner, expect an operation context, so an empty one is set.
+func dispatchError(ctx context.Context, exec graphql.GraphExecutor, errs gqlerror.List) *graphql.Response {
+	return exec.DispatchError(graphql.WithOperationContext(ctx, &graphql.OperationContext{}), errs)
+}
+
+func writeJSON(w io.Writer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	_, _ = w.Write(b)
+}
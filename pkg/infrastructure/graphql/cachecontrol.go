@@ -0,0 +1,161 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gitlab.com/trustify/core/pkg/infrastructure/cache"
+)
+
+const (
+	// directiveCacheControl is the name of the directive holding the caching hints
+	directiveCacheControl = "cacheControl"
+	// interfaceNode is the interface implemented by the entity types
+	interfaceNode = "Node"
+)
+
+// containerSuffixes are the suffixes of the types which contain entities of the type named by the rest of the name,
+// e.g. UserConnection and UserAggregate contain or are computed from users
+var containerSuffixes = []string{"SearchConnection", "SearchEdge", "Connection", "Edge", "Aggregate"}
+
+// cacheControlExtension is a graphql extension computing the cache policy of a response from the @cacheControl hints.
+// The policy is read from the context if the router created it, so that it can set the Cache-Control header.
+type cacheControlExtension struct {
+	schema *ast.Schema
+	// entities maps the types of the schema to the entity types which they contain
+	entities map[string][]string
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = &cacheControlExtension{}
+
+// ExtensionName implements graphql.HandlerExtension
+func (*cacheControlExtension) ExtensionName() string {
+	return "CacheControl"
+}
+
+// Validate implements graphql.HandlerExtension
+func (e *cacheControlExtension) Validate(schema graphql.ExecutableSchema) error {
+	e.schema = schema.Schema()
+	e.entities = entityTypes(e.schema)
+	return nil
+}
+
+// InterceptResponse implements graphql.ResponseInterceptor
+func (e *cacheControlExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	policy := cache.PolicyFromContext(ctx)
+	if policy == nil {
+		policy = cache.NewPolicy()
+		ctx = cache.WithPolicy(ctx, policy)
+	}
+
+	if graphql.HasOperationContext(ctx) {
+		oc := graphql.GetOperationContext(ctx)
+		if oc.Operation == nil || oc.Operation.Operation != ast.Query {
+			policy.NoStore()
+		}
+	}
+
+	resp := next(ctx)
+	if resp == nil || len(resp.Errors) > 0 {
+		policy.NoStore()
+	}
+
+	return resp
+}
+
+// InterceptField implements graphql.FieldInterceptor
+func (e *cacheControlExtension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	policy := cache.PolicyFromContext(ctx)
+	fc := graphql.GetFieldContext(ctx)
+	if policy == nil || fc == nil || fc.Field.Definition == nil {
+		return next(ctx)
+	}
+
+	// responses are tagged with the entities returned by the fields, so that they are invalidated by mutations
+	// of these entities even if no field of the entity type is selected, e.g. for totalCount of a connection
+	for _, entity := range e.entities[fc.Field.Definition.Type.Name()] {
+		policy.AddType(entity)
+	}
+
+	root := e.isRoot(fc.Object)
+
+	hint := fc.Field.Definition.Directives.ForName(directiveCacheControl)
+	if hint == nil {
+		if def := e.schema.Types[fc.Field.Definition.Type.Name()]; def != nil {
+			hint = def.Directives.ForName(directiveCacheControl)
+		}
+	}
+
+	switch {
+	case hint != nil:
+		if maxAge := hint.Arguments.ForName("maxAge"); maxAge != nil && maxAge.Value != nil {
+			if v, err := strconv.Atoi(maxAge.Value.Raw); err == nil {
+				policy.RestrictMaxAge(v)
+			}
+		}
+		if scope := hint.Arguments.ForName("scope"); scope != nil && scope.Value != nil {
+			policy.RestrictScope(cache.Scope(scope.Value.Raw))
+		}
+	case root:
+		policy.RestrictMaxAge(0)
+	}
+
+	return next(ctx)
+}
+
+// isRoot reports whether the object is a root operation type
+func (e *cacheControlExtension) isRoot(object string) bool {
+	for _, def := range []*ast.Definition{e.schema.Query, e.schema.Mutation, e.schema.Subscription} {
+		if def != nil && def.Name == object {
+			return true
+		}
+	}
+	return false
+}
+
+// entityTypes maps every type of the schema to the entity types which it contains.
+// Entities are the objects implementing Node; interfaces and unions contain their entities
+// and containers such as connections and aggregates contain the entity named by their prefix.
+func entityTypes(schema *ast.Schema) map[string][]string {
+	isEntity := func(name string) bool {
+		def := schema.Types[name]
+		if def == nil || def.Kind != ast.Object {
+			return false
+		}
+		for _, i := range def.Interfaces {
+			if i == interfaceNode {
+				return true
+			}
+		}
+		return false
+	}
+
+	result := map[string][]string{}
+	for name, def := range schema.Types {
+		switch {
+		case isEntity(name):
+			result[name] = []string{name}
+		case def.Kind == ast.Interface || def.Kind == ast.Union:
+			for _, t := range schema.GetPossibleTypes(def) {
+				if isEntity(t.Name) {
+					result[name] = append(result[name], t.Name)
+				}
+			}
+		default:
+			for _, suffix := range containerSuffixes {
+				if prefix := strings.TrimSuffix(name, suffix); prefix != name && isEntity(prefix) {
+					result[name] = []string{prefix}
+					break
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestEntityTypes(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+		interface Node { id: ID! }
+		type User implements Node { id: ID! }
+		type UserEdge { node: User }
+		type UserConnection { edges: [UserEdge] totalCount: Int! }
+		type UserSearchConnection { totalCount: Int! }
+		type UserAggregate { count: Int! }
+		type PageInfo { hasNextPage: Boolean! }
+		type Query { node(id: ID!): Node users: UserConnection }
+	`})
+	got := entityTypes(schema)
+
+	tests := []struct {
+		name string
+		args string
+		want []string
+	}{
+		{name: "it should map entities to themselves", args: "User", want: []string{"User"}},
+		{name: "it should map interfaces to their entities", args: "Node", want: []string{"User"}},
+		{name: "it should map connections to their entity", args: "UserConnection", want: []string{"User"}},
+		{name: "it should map edges to their entity", args: "UserEdge", want: []string{"User"}},
+		{name: "it should map search connections to their entity", args: "UserSearchConnection", want: []string{"User"}},
+		{name: "it should map aggregates to their entity", args: "UserAggregate", want: []string{"User"}},
+		{name: "it should not map other types", args: "PageInfo", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, got[tt.args])
+		})
+	}
+}
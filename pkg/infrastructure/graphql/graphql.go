@@ -1,17 +1,104 @@
 package graphql
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
 	"entgo.io/contrib/entgql"
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gitlab.com/trustify/core/config"
 	"gitlab.com/trustify/core/ent"
 	"gitlab.com/trustify/core/pkg/adapter/controller"
+	"gitlab.com/trustify/core/pkg/adapter/directives"
 	"gitlab.com/trustify/core/pkg/adapter/resolver"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/infrastructure/idempotency"
+	"gitlab.com/trustify/core/pkg/util/environment"
+	"gitlab.com/trustify/core/pkg/util/i18n"
 )
 
-// NewServer generates graphql server
+// internalErrorMessage replaces the message of internal errors in production
+const internalErrorMessage = "internal server error"
+
+// NewServer generates graphql server.
+// It is set up like handler.NewDefaultServer and also accepts batches of operations in POST requests.
 func NewServer(client *ent.Client, controller controller.Controller) *handler.Server {
-	srv := handler.NewDefaultServer(resolver.NewSchema(client, controller))
+	srv := handler.New(resolver.NewSchema(client, controller))
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(batchPOST{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{
+		MaxUploadSize: config.C.GraphQL.MaxUploadSize,
+	})
+	srv.SetQueryCache(lru.New(1000))
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New(100),
+	})
+
+	srv.Use(tracingExtension{})
+	srv.Use(metricsExtension{})
+	srv.Use(loggingExtension{})
+	srv.Use(queryStatsExtension{})
+	srv.Use(&cacheControlExtension{})
+	// the client in the context is read by validations, mutations read the client of their transaction
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(ent.NewContext(ctx, client))
+	})
 	srv.Use(entgql.Transactioner{TxOpener: client})
+	srv.Use(idempotencyExtension{store: idempotency.NewStore(client)})
+	srv.Use(&directives.BindingErrors{})
+	srv.SetErrorPresenter(errorPresenter)
 
 	return srv
 }
+
+// errorPresenter adds the domain error code to the extensions of every error.
+// Codes set by libraries are kept. Internal errors are logged as errors and their message is masked in production.
+// Messages are translated to the locale negotiated for the request.
+func errorPresenter(ctx context.Context, e error) *gqlerror.Error {
+	err := graphql.DefaultErrorPresenter(ctx, e)
+	code := model.ErrorCodeOf(e)
+
+	var cause error
+	var appErr *model.Error
+	if errors.As(e, &appErr) {
+		cause = appErr.Err
+	} else if c, ok := err.Extensions["code"].(string); ok {
+		// keep codes set by libraries, e.g. validation errors of gqlgen or NOT_FOUND of entgql
+		code = model.ErrorCode(c)
+	}
+	level := slog.LevelInfo
+	if code == model.CodeInternal {
+		level = slog.LevelError
+	}
+	slog.Log(ctx, level, "graphql error",
+		slog.String("code", string(code)),
+		slog.String("path", err.Path.String()),
+		slog.String("message", err.Message),
+		slog.Any("cause", cause),
+	)
+	errorsTotal.WithLabelValues(string(code)).Inc()
+
+	if code == model.CodeInternal && environment.IsProd() {
+		err.Message = internalErrorMessage
+	}
+	err.Message = i18n.Translate(i18n.LocaleFromContext(ctx), err.Message)
+	if err.Extensions == nil {
+		err.Extensions = map[string]interface{}{}
+	}
+	err.Extensions["code"] = code
+
+	return err
+}
@@ -0,0 +1,173 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/infrastructure/idempotency"
+)
+
+// extensionIdempotentReplay is set in the extensions of responses replayed for an idempotency key
+const extensionIdempotentReplay = "idempotentReplay"
+
+// idempotencyExtension is a graphql extension replaying the response of a mutation sent again with the same Idempotency-Key.
+// It must be used after entgql.Transactioner, so that the key and the response are stored in the transaction of the mutation.
+type idempotencyExtension struct {
+	store *idempotency.Store
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = idempotencyExtension{}
+
+// ExtensionName implements graphql.HandlerExtension
+func (idempotencyExtension) ExtensionName() string {
+	return "Idempotency"
+}
+
+// Validate implements graphql.HandlerExtension
+func (idempotencyExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse implements graphql.ResponseInterceptor.
+// Responses with errors are not stored, their transaction is rolled back and the mutation may be retried with the key.
+func (e idempotencyExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	key := idempotency.KeyFromContext(ctx)
+	if key == "" || !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation != ast.Mutation {
+		return next(ctx)
+	}
+
+	hash, err := operationHash(oc)
+	if err != nil {
+		return errorResponse(ctx, model.NewInternalError(err, "failed to hash operation"))
+	}
+	stored, err := e.store.Begin(ctx, key, hash)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+	if stored != nil {
+		var resp graphql.Response
+		if err := json.Unmarshal(stored, &resp); err != nil {
+			return errorResponse(ctx, model.NewInternalError(err, "failed to replay response"))
+		}
+		if resp.Extensions == nil {
+			resp.Extensions = map[string]interface{}{}
+		}
+		resp.Extensions[extensionIdempotentReplay] = true
+		return &resp
+	}
+
+	resp := next(ctx)
+	if resp == nil || len(resp.Errors) > 0 {
+		return resp
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return errorResponse(ctx, model.NewInternalError(err, "failed to store response"))
+	}
+	if err := e.store.Finish(ctx, key, b); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	return resp
+}
+
+// errorResponse responds with the error formatted by the error presenter
+func errorResponse(ctx context.Context, err error) *graphql.Response {
+	graphql.AddError(ctx, err)
+	return &graphql.Response{Errors: graphql.GetErrors(ctx)}
+}
+
+// operationHash identifies an operation by its query, its name and its variables
+func operationHash(oc *graphql.OperationContext) (string, error) {
+	variables, err := hashableValue(oc.Variables)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(variables)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(oc.RawQuery))
+	h.Write([]byte{0})
+	h.Write([]byte(oc.OperationName))
+	h.Write([]byte{0})
+	h.Write(b)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashableValue replaces the uploads in the variables by the digest of their content, which cannot be encoded as JSON.
+// JSON encodes maps with sorted keys, so equal variables are encoded equally.
+func hashableValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			hv, err := hashableValue(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = hv
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, value := range v {
+			hv, err := hashableValue(value)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = hv
+		}
+		return l, nil
+	case graphql.Upload:
+		return uploadDigest(v)
+	case *graphql.Upload:
+		if v == nil {
+			return nil, nil
+		}
+		return uploadDigest(*v)
+	default:
+		return v, nil
+	}
+}
+
+// uploadDigest returns the name and the content digest of an upload and rewinds it for the resolver.
+// Uploads which cannot be rewound, i.e. a file mapped to several variables, are identified by name and size only.
+func uploadDigest(u graphql.Upload) (interface{}, error) {
+	digest := map[string]interface{}{
+		"filename":    u.Filename,
+		"size":        u.Size,
+		"contentType": u.ContentType,
+	}
+	seeker, ok := u.File.(io.Seeker)
+	if !ok {
+		return digest, nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, u.File); err != nil {
+		return nil, err
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	digest["sha256"] = hex.EncodeToString(h.Sum(nil))
+
+	return digest, nil
+}
@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"gitlab.com/trustify/core/pkg/util/logger"
+)
+
+// loggingExtension is a graphql extension logging every operation.
+// Variables are logged with the values of sensitive fields like passwords redacted.
+type loggingExtension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = loggingExtension{}
+
+// ExtensionName implements graphql.HandlerExtension
+func (loggingExtension) ExtensionName() string {
+	return "Logging"
+}
+
+// Validate implements graphql.HandlerExtension
+func (loggingExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse implements graphql.ResponseInterceptor
+func (loggingExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+
+	start := time.Now()
+	resp := next(ctx)
+
+	operationType := ""
+	if oc.Operation != nil {
+		operationType = string(oc.Operation.Operation)
+	}
+	errors := 0
+	if resp != nil {
+		errors = len(resp.Errors)
+	}
+	slog.InfoContext(ctx, "graphql operation",
+		slog.String("operation", oc.OperationName),
+		slog.String("type", operationType),
+		slog.Any("variables", logger.Redact(oc.Variables)),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("errors", errors),
+	)
+
+	return resp
+}
@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/util/metrics"
+)
+
+const (
+	// anonymousOperation is the operation label of operations without a name
+	anonymousOperation = "anonymous"
+	// otherOperation is the operation label of named operations which are not in config.C.Metrics.Operations.
+	// Names are chosen by clients, so labeling every name would create unbounded series.
+	otherOperation = "other"
+)
+
+var (
+	operationsTotal = metrics.NewCounter(
+		"graphql_operations_total",
+		"Number of executed GraphQL operations",
+		"operation", "type",
+	)
+	operationDuration = metrics.NewHistogram(
+		"graphql_operation_duration_seconds",
+		"Duration of GraphQL operations in seconds",
+		"operation", "type",
+	)
+	fieldDuration = metrics.NewHistogram(
+		"graphql_field_duration_seconds",
+		"Duration of GraphQL resolvers in seconds",
+		"object", "field",
+	)
+	errorsTotal = metrics.NewCounter(
+		"graphql_errors_total",
+		"Number of GraphQL errors by the code of their extensions",
+		"code",
+	)
+)
+
+// metricsExtension is a graphql extension recording the count and duration of operations and resolvers.
+// Fields which are read from a struct without a resolver are not measured.
+type metricsExtension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = metricsExtension{}
+
+// ExtensionName implements graphql.HandlerExtension
+func (metricsExtension) ExtensionName() string {
+	return "Metrics"
+}
+
+// Validate implements graphql.HandlerExtension
+func (metricsExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse implements graphql.ResponseInterceptor
+func (metricsExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+
+	name := oc.OperationName
+	operationType := ""
+	if oc.Operation != nil {
+		operationType = string(oc.Operation.Operation)
+		if name == "" {
+			name = oc.Operation.Name
+		}
+	}
+	name = operationLabel(name)
+
+	start := time.Now()
+	resp := next(ctx)
+	operationsTotal.WithLabelValues(name, operationType).Inc()
+	operationDuration.WithLabelValues(name, operationType).Observe(time.Since(start).Seconds())
+
+	return resp
+}
+
+// operationLabel returns the label of the operation name, which is the name itself only if it is allowed
+func operationLabel(name string) string {
+	if name == "" {
+		return anonymousOperation
+	}
+	for _, allowed := range config.C.Metrics.Operations {
+		if name == allowed {
+			return name
+		}
+	}
+	return otherOperation
+}
+
+// InterceptField implements graphql.FieldInterceptor
+func (metricsExtension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || (!fc.IsResolver && !fc.IsMethod) {
+		return next(ctx)
+	}
+
+	start := time.Now()
+	res, err := next(ctx)
+	fieldDuration.WithLabelValues(fc.Object, fc.Field.Name).Observe(time.Since(start).Seconds())
+
+	return res, err
+}
@@ -0,0 +1,30 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/trustify/core/config"
+)
+
+func TestOperationLabel(t *testing.T) {
+	operations := config.C.Metrics.Operations
+	config.C.Metrics.Operations = []string{"Users"}
+	defer func() { config.C.Metrics.Operations = operations }()
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{name: "it should label allowed operations with their name", args: "Users", want: "Users"},
+		{name: "it should label other operations as other", args: "UsersOf123", want: otherOperation},
+		{name: "it should label unnamed operations as anonymous", args: "", want: anonymousOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, operationLabel(tt.args))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"gitlab.com/trustify/core/pkg/infrastructure/datastore"
+	"gitlab.com/trustify/core/pkg/util/environment"
+)
+
+// queryStatsExtension is a graphql extension recording the SQL statements of every operation.
+// In development the number of statements and the likely N+1 queries are added to the extensions of the response.
+type queryStatsExtension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = queryStatsExtension{}
+
+// ExtensionName implements graphql.HandlerExtension
+func (queryStatsExtension) ExtensionName() string {
+	return "QueryStats"
+}
+
+// Validate implements graphql.HandlerExtension
+func (queryStatsExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse implements graphql.ResponseInterceptor
+func (queryStatsExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	ctx, stats := datastore.WithQueryStats(ctx)
+
+	resp := next(ctx)
+	if resp == nil || !environment.IsDev() {
+		return resp
+	}
+	if resp.Extensions == nil {
+		resp.Extensions = map[string]interface{}{}
+	}
+	resp.Extensions["queries"] = stats.Summary()
+
+	return resp
+}
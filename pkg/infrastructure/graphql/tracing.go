@@ -0,0 +1,97 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"gitlab.com/trustify/core/pkg/infrastructure/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes of GraphQL spans
+const (
+	attrOperationName = attribute.Key("graphql.operation.name")
+	attrOperationType = attribute.Key("graphql.operation.type")
+	attrFieldPath     = attribute.Key("graphql.field.path")
+	attrFieldName     = attribute.Key("graphql.field.name")
+	attrFieldObject   = attribute.Key("graphql.field.object")
+)
+
+// tracingExtension is a graphql extension creating a span for every operation and for every resolver.
+// Fields which are read from a struct without a resolver are not traced.
+type tracingExtension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = tracingExtension{}
+
+// ExtensionName implements graphql.HandlerExtension
+func (tracingExtension) ExtensionName() string {
+	return "Tracing"
+}
+
+// Validate implements graphql.HandlerExtension
+func (tracingExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse implements graphql.ResponseInterceptor
+func (tracingExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+
+	name := oc.OperationName
+	operationType := ""
+	if oc.Operation != nil {
+		operationType = string(oc.Operation.Operation)
+		if name == "" {
+			name = oc.Operation.Name
+		}
+	}
+	spanName := "graphql " + operationType
+	if name != "" {
+		spanName += " " + name
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, spanName, trace.WithAttributes(
+		attrOperationName.String(name),
+		attrOperationType.String(operationType),
+	))
+	defer span.End()
+
+	resp := next(ctx)
+	if resp != nil && len(resp.Errors) > 0 {
+		span.SetStatus(codes.Error, resp.Errors.Error())
+	}
+
+	return resp
+}
+
+// InterceptField implements graphql.FieldInterceptor
+func (tracingExtension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || (!fc.IsResolver && !fc.IsMethod) {
+		return next(ctx)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, fc.Object+"."+fc.Field.Name, trace.WithAttributes(
+		attrFieldPath.String(fc.Path().String()),
+		attrFieldName.String(fc.Field.Name),
+		attrFieldObject.String(fc.Object),
+	))
+	defer span.End()
+
+	res, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return res, err
+}
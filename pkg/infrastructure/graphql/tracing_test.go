@@ -0,0 +1,150 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTracedServer returns a server of a schema with the resolver Query.user, which calls resolve
+func newTracedServer(resolve func(ctx context.Context) (interface{}, error)) *handler.Server {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `type Query { user: String }`})
+	srv := handler.New(&graphql.ExecutableSchemaMock{
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			ran := false
+			return func(ctx context.Context) *graphql.Response {
+				if ran {
+					return nil
+				}
+				ran = true
+				ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{
+					Object:     "Query",
+					IsResolver: true,
+					Field: graphql.CollectedField{Field: &ast.Field{
+						Name:       "user",
+						Alias:      "user",
+						Definition: schema.Query.Fields.ForName("user"),
+					}},
+				})
+				_, err := graphql.GetOperationContext(ctx).ResolverMiddleware(ctx, resolve)
+				if err != nil {
+					graphql.AddError(ctx, err)
+					return &graphql.Response{Data: []byte(`{"user":null}`)}
+				}
+				return &graphql.Response{Data: []byte(`{"user":"John"}`)}
+			}
+		},
+		SchemaFunc: func() *ast.Schema {
+			return schema
+		},
+		ComplexityFunc: func(string, string, int, map[string]interface{}) (int, bool) {
+			return 0, false
+		},
+	})
+	srv.AddTransport(transport.POST{})
+	srv.Use(tracingExtension{})
+
+	return srv
+}
+
+// spanByName returns the ended span with the name
+func spanByName(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, s := range spans {
+		if s.Name() == name {
+			return s
+		}
+	}
+	t.Fatalf("no span %q", name)
+	return nil
+}
+
+func TestTracingExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		resolve func(ctx context.Context) (interface{}, error)
+		assert  func(t *testing.T, spans []sdktrace.ReadOnlySpan)
+	}{
+		{
+			name: "it should trace the operation and its resolvers with their attributes",
+			resolve: func(ctx context.Context) (interface{}, error) {
+				_, span := otel.Tracer("test").Start(ctx, "sql SELECT")
+				span.End()
+				return "John", nil
+			},
+			assert: func(t *testing.T, spans []sdktrace.ReadOnlySpan) {
+				require.Len(t, spans, 3)
+				operation := spanByName(t, spans, "graphql query GetUser")
+				resolver := spanByName(t, spans, "Query.user")
+				statement := spanByName(t, spans, "sql SELECT")
+
+				assert.False(t, operation.Parent().IsValid())
+				assert.Equal(t, operation.SpanContext().SpanID(), resolver.Parent().SpanID())
+				assert.Equal(t, resolver.SpanContext().SpanID(), statement.Parent().SpanID())
+				assert.Equal(t, operation.SpanContext().TraceID(), statement.SpanContext().TraceID())
+
+				assert.ElementsMatch(t, []attribute.KeyValue{
+					attrOperationName.String("GetUser"),
+					attrOperationType.String("query"),
+				}, operation.Attributes())
+				assert.ElementsMatch(t, []attribute.KeyValue{
+					attrFieldPath.String("user"),
+					attrFieldName.String("user"),
+					attrFieldObject.String("Query"),
+				}, resolver.Attributes())
+				assert.Equal(t, codes.Unset, operation.Status().Code)
+				assert.Equal(t, codes.Unset, resolver.Status().Code)
+			},
+		},
+		{
+			name: "it should record errors of resolvers",
+			resolve: func(ctx context.Context) (interface{}, error) {
+				return nil, errors.New("user not found")
+			},
+			assert: func(t *testing.T, spans []sdktrace.ReadOnlySpan) {
+				require.Len(t, spans, 2)
+				operation := spanByName(t, spans, "graphql query GetUser")
+				resolver := spanByName(t, spans, "Query.user")
+
+				assert.Equal(t, codes.Error, resolver.Status().Code)
+				assert.Equal(t, "user not found", resolver.Status().Description)
+				if assert.Len(t, resolver.Events(), 1) {
+					assert.Equal(t, "exception", resolver.Events()[0].Name)
+				}
+				assert.Equal(t, codes.Error, operation.Status().Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			global := otel.GetTracerProvider()
+			defer otel.SetTracerProvider(global)
+			sr := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+
+			req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query":"query GetUser { user }","operationName":"GetUser"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			newTracedServer(tt.resolve).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			tt.assert(t, sr.Ended())
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/idempotencykey"
+	entschema "gitlab.com/trustify/core/ent/schema"
+	"gitlab.com/trustify/core/pkg/entity/model"
+)
+
+// Header is the request header holding the idempotency key of a mutation
+const Header = "Idempotency-Key"
+
+type keyKey struct{}
+
+// WithKey returns a context holding the idempotency key of the request
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyKey{}, key)
+}
+
+// KeyFromContext returns the idempotency key of the context or an empty string
+func KeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(keyKey{}).(string)
+	return key
+}
+
+// Store keeps the responses of mutations by their idempotency key.
+// It uses the transaction of the context, which entgql.Transactioner opens for mutations,
+// so that a key is only stored if the changes of its mutation are committed.
+type Store struct {
+	client *ent.Client
+}
+
+// NewStore returns a store of the keys in the database of the client
+func NewStore(client *ent.Client) *Store {
+	return &Store{client: client}
+}
+
+// Begin claims the key for the operation identified by hash.
+// If a response was stored for the key, it is returned and the operation must not be executed again.
+// A key used with a different operation is a conflict. A concurrent request with the same key
+// blocks Begin until its transaction ends and is a conflict if it stored a response.
+func (s *Store) Begin(ctx context.Context, key string, hash string) ([]byte, error) {
+	if len(key) > entschema.IdempotencyKeyMaxLength {
+		return nil, model.NewValidationError(fmt.Errorf("key has %d characters", len(key)), "idempotency key must not be longer than 255 characters")
+	}
+	client := s.txClient(ctx)
+
+	k, err := client.IdempotencyKey.Query().Where(idempotencykey.Key(key)).Only(ctx)
+	if err != nil && !ent.IsNotFound(err) {
+		return nil, model.NewDBError(err, "failed to check idempotency key")
+	}
+	if k != nil {
+		if k.ExpiresAt.After(time.Now()) {
+			if k.OperationHash != hash {
+				return nil, model.NewConflictError(nil, "idempotency key was used with a different operation")
+			}
+			return k.Response, nil
+		}
+		if err := client.IdempotencyKey.DeleteOne(k).Exec(ctx); err != nil {
+			return nil, model.NewDBError(err, "failed to check idempotency key")
+		}
+	}
+
+	_, err = client.IdempotencyKey.Create().
+		SetKey(key).
+		SetOperationHash(hash).
+		SetExpiresAt(time.Now().Add(config.C.Idempotency.Expiry)).
+		Save(ctx)
+	if ent.IsConstraintError(err) {
+		return nil, model.NewConflictError(err, "idempotency key was used by a concurrent request")
+	}
+	if err != nil {
+		return nil, model.NewDBError(err, "failed to check idempotency key")
+	}
+
+	return nil, nil
+}
+
+// Finish stores the response of the operation of a key claimed by Begin
+func (s *Store) Finish(ctx context.Context, key string, response []byte) error {
+	_, err := s.txClient(ctx).IdempotencyKey.Update().
+		Where(idempotencykey.Key(key)).
+		SetResponse(response).
+		Save(ctx)
+	if err != nil {
+		return model.NewDBError(err, "failed to store idempotency key")
+	}
+
+	return nil
+}
+
+// Purge deletes the expired keys and returns their number
+func (s *Store) Purge(ctx context.Context) (int, error) {
+	n, err := s.client.IdempotencyKey.Delete().Where(idempotencykey.ExpiresAtLT(time.Now())).Exec(ctx)
+	if err != nil {
+		return 0, model.NewDBError(err, "failed to purge idempotency keys")
+	}
+
+	return n, nil
+}
+
+// PurgeEvery purges the expired keys every interval until the context is done
+func (s *Store) PurgeEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Purge(ctx)
+			if err != nil {
+				slog.Error("failed purging idempotency keys", slog.Any("error", err))
+				continue
+			}
+			slog.Debug("purged idempotency keys", slog.Int("count", n))
+		}
+	}
+}
+
+// txClient returns the client of the transaction in the context, if any
+func (s *Store) txClient(ctx context.Context) *ent.Client {
+	if tx := ent.TxFromContext(ctx); tx != nil {
+		return tx.Client()
+	}
+	return s.client
+}
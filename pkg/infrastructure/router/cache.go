@@ -0,0 +1,93 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gitlab.com/trustify/core/pkg/infrastructure/cache"
+	"gitlab.com/trustify/core/pkg/util/i18n"
+)
+
+// HeaderXCache tells whether a response was served from the response cache
+const HeaderXCache = "X-Cache"
+
+// cacheMiddleware sets the Cache-Control header from the cache policy of the response.
+// Responses of anonymous GET requests are served from responseCache if the policy allows shared caching.
+// A nil responseCache disables caching of responses.
+func cacheMiddleware(responseCache cache.Cache) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			res := c.Response()
+			ctx := req.Context()
+
+			policy := cache.NewPolicy()
+			c.SetRequest(req.WithContext(cache.WithPolicy(ctx, policy)))
+			res.Before(func() {
+				if res.Header().Get(echo.HeaderCacheControl) == "" {
+					res.Header().Set(echo.HeaderCacheControl, policy.Header())
+				}
+			})
+
+			if responseCache == nil || !isAnonymousGET(req) {
+				return next(c)
+			}
+
+			key := cacheKey(req)
+			if entry, ok := responseCache.Get(ctx, key); ok {
+				res.Header().Set(echo.HeaderCacheControl, entry.CacheControl)
+				res.Header().Set(HeaderXCache, "HIT")
+				return c.Blob(http.StatusOK, entry.ContentType, entry.Body)
+			}
+
+			body := &bytes.Buffer{}
+			res.Writer = &teeWriter{ResponseWriter: res.Writer, body: body}
+			res.Header().Set(HeaderXCache, "MISS")
+			if err := next(c); err != nil {
+				return err
+			}
+
+			if res.Status == http.StatusOK && policy.Shared() {
+				responseCache.Set(ctx, key, &cache.Entry{
+					ContentType:  res.Header().Get(echo.HeaderContentType),
+					CacheControl: policy.Header(),
+					Body:         body.Bytes(),
+					Types:        policy.Types(),
+				}, time.Duration(policy.MaxAge())*time.Second)
+			}
+
+			return nil
+		}
+	}
+}
+
+// isAnonymousGET reports whether the request is a GET request without credentials
+func isAnonymousGET(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get(echo.HeaderAuthorization) == "" &&
+		req.Header.Get(echo.HeaderCookie) == ""
+}
+
+// cacheKey identifies the response of a request by its query parameters and its locale
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.URL.Query().Encode()))
+	h.Write([]byte{0})
+	h.Write([]byte(i18n.LocaleFromContext(req.Context())))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// teeWriter copies the body of the response
+type teeWriter struct {
+	http.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
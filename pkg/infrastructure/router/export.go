@@ -0,0 +1,206 @@
+package router
+
+import (
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/adapter/controller"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/util/i18n"
+)
+
+// Formats of exports
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// exportedUser is the record of a user in exports, it lists every exported field explicitly
+// so that fields added to the schema are not exported by accident
+type exportedUser struct {
+	ID        model.ID  `json:"id"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var exportedUserHeader = []string{"id", "firstName", "lastName", "email", "createdAt", "updatedAt"}
+
+func newExportedUser(u *model.User) exportedUser {
+	return exportedUser{
+		ID:        u.ID,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+// userWriter encodes users in the format of an export
+type userWriter interface {
+	Write(u exportedUser) error
+	Flush() error
+}
+
+type csvUserWriter struct {
+	w *csv.Writer
+}
+
+func newCSVUserWriter(w io.Writer) (userWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportedUserHeader); err != nil {
+		return nil, err
+	}
+	return &csvUserWriter{w: cw}, nil
+}
+
+func (w *csvUserWriter) Write(u exportedUser) error {
+	return w.w.Write([]string{
+		string(u.ID),
+		u.FirstName,
+		u.LastName,
+		u.Email,
+		u.CreatedAt.Format(time.RFC3339Nano),
+		u.UpdatedAt.Format(time.RFC3339Nano),
+	})
+}
+
+func (w *csvUserWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonUserWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONUserWriter(w io.Writer) (userWriter, error) {
+	return &ndjsonUserWriter{enc: json.NewEncoder(w)}, nil
+}
+
+func (w *ndjsonUserWriter) Write(u exportedUser) error {
+	return w.enc.Encode(u)
+}
+
+func (w *ndjsonUserWriter) Flush() error {
+	return nil
+}
+
+// exportFormat is a supported format of exports
+type exportFormat struct {
+	contentType string
+	newWriter   func(w io.Writer) (userWriter, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	ExportFormatCSV:    {contentType: "text/csv; charset=utf-8", newWriter: newCSVUserWriter},
+	ExportFormatNDJSON: {contentType: "application/x-ndjson", newWriter: newNDJSONUserWriter},
+}
+
+// exportAuth only lets requests with one of the bearer tokens in config.C.Export.Tokens pass
+func exportAuth() echo.MiddlewareFunc {
+	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		Validator: func(key string, _ echo.Context) (bool, error) {
+			for _, token := range config.C.Export.Tokens {
+				if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	})
+}
+
+// exportUsers streams the users matching the JSON encoded UserWhereInput of the where parameter.
+// The format parameter is csv (default) or ndjson. Every batch of users is flushed to the client,
+// errors after the first batch abort the response.
+func exportUsers(ctrl controller.Controller) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		locale := i18n.LocaleFromContext(ctx)
+
+		name := c.QueryParam("format")
+		if name == "" {
+			name = ExportFormatCSV
+		}
+		format, ok := exportFormats[name]
+		if !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, i18n.Translate(locale, "format must be csv or ndjson"))
+		}
+
+		var where *model.UserWhereInput
+		if raw := c.QueryParam("where"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &where); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, i18n.Translate(locale, "invalid user filter")).SetInternal(err)
+			}
+		}
+
+		res := c.Response()
+		var w userWriter
+		start := func() error {
+			res.Header().Set(echo.HeaderContentType, format.contentType)
+			res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "users."+name))
+			res.WriteHeader(http.StatusOK)
+			var err error
+			w, err = format.newWriter(res)
+			return err
+		}
+
+		err := ctrl.User.Export(ctx, where, func(users []*model.User) error {
+			if w == nil {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			for _, u := range users {
+				if err := w.Write(newExportedUser(u)); err != nil {
+					return err
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			res.Flush()
+			return nil
+		})
+		if err != nil {
+			if res.Committed {
+				return err
+			}
+			return exportError(locale, err)
+		}
+		if w == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		return w.Flush()
+	}
+}
+
+// exportError maps a domain error to an HTTP error with a translated message
+func exportError(locale string, err error) error {
+	var e *model.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	status := http.StatusInternalServerError
+	if e.Code == model.CodeValidation {
+		status = http.StatusBadRequest
+	}
+
+	return echo.NewHTTPError(status, i18n.Translate(locale, e.Message)).SetInternal(err)
+}
@@ -1,37 +1,119 @@
 package router
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/adapter/controller"
+	"gitlab.com/trustify/core/pkg/infrastructure/cache"
+	"gitlab.com/trustify/core/pkg/infrastructure/idempotency"
+	"gitlab.com/trustify/core/pkg/infrastructure/storage"
+	"gitlab.com/trustify/core/pkg/util/i18n"
+	"gitlab.com/trustify/core/pkg/util/logger"
+	"gitlab.com/trustify/core/pkg/util/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 )
 
 // Path of route
 const (
 	QueryPath      = "/query"
 	PlaygroundPath = "/playground"
+	MetricsPath    = "/metrics"
+	ExportPath     = "/export/users"
 )
 
-// New creates route endpoint
-func New(srv *handler.Server) *echo.Echo {
+// HeaderAcceptLanguage is used to negotiate the locale of messages
+const HeaderAcceptLanguage = "Accept-Language"
+
+// New creates route endpoint.
+// Responses of anonymous GET queries are cached in responseCache unless it is nil.
+// files serves the signed URLs of a storage.Local, it is nil if files are stored elsewhere.
+// ctrl serves the REST endpoints which are not part of the GraphQL schema.
+func New(srv *handler.Server, responseCache cache.Cache, files http.Handler, ctrl controller.Controller) *echo.Echo {
 	e := echo.New()
 	e.Use(middleware.Recover())
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logger.WithRequestID(req.Context(), id)))
+		},
+	}))
+	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogMethod:     true,
+		LogURIPath:    true,
+		LogStatus:     true,
+		LogLatency:    true,
+		LogError:      true,
+		LogValuesFunc: logRequest,
+	}))
+	e.Use(otelecho.Middleware(config.C.Tracing.ServiceName))
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderXRequestedWith, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderXRequestedWith, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, HeaderAcceptLanguage, echo.HeaderXRequestID, idempotency.Header},
+		ExposeHeaders: []string{echo.HeaderXRequestID},
 	}))
+	e.Use(localeMiddleware)
+	e.Use(idempotencyMiddleware)
 
 	{
-		e.POST(QueryPath, echo.WrapHandler(srv))
+		e.GET(QueryPath, echo.WrapHandler(srv), cacheMiddleware(responseCache))
+		e.POST(QueryPath, echo.WrapHandler(srv), cacheMiddleware(responseCache))
 		e.GET(PlaygroundPath, func(c echo.Context) error {
 			playground.Handler("GraphQL Playground", QueryPath).ServeHTTP(c.Response(), c.Request())
 			return nil
 		})
+		e.GET(MetricsPath, echo.WrapHandler(metrics.Handler()))
+		e.GET(ExportPath, exportUsers(ctrl), exportAuth())
+		if files != nil {
+			e.GET(storage.LocalPath+"*", echo.WrapHandler(http.StripPrefix(storage.LocalPath, files)))
+		}
 	}
 
 	return e
 }
+
+// logRequest writes the access log of a request
+func logRequest(c echo.Context, v middleware.RequestLoggerValues) error {
+	level := slog.LevelInfo
+	if v.Error != nil || v.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(c.Request().Context(), level, "request",
+		slog.String("method", v.Method),
+		slog.String("path", v.URIPath),
+		slog.Int("status", v.Status),
+		slog.Duration("latency", v.Latency),
+		slog.Any("error", v.Error),
+	)
+
+	return nil
+}
+
+// localeMiddleware stores the locale negotiated from the Accept-Language header in the request context
+func localeMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		locale := i18n.Negotiate(req.Header.Get(HeaderAcceptLanguage))
+		c.SetRequest(req.WithContext(i18n.WithLocale(req.Context(), locale)))
+
+		return next(c)
+	}
+}
+
+// idempotencyMiddleware stores the Idempotency-Key header in the request context
+func idempotencyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if key := req.Header.Get(idempotency.Header); key != "" {
+			c.SetRequest(req.WithContext(idempotency.WithKey(req.Context(), key)))
+		}
+
+		return next(c)
+	}
+}
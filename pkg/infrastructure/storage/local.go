@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/trustify/core/pkg/usercase/repository"
+)
+
+// Local stores files in a directory of the local filesystem.
+// It serves the files itself, signed URLs point to its ServeHTTP mounted at LocalPath.
+type Local struct {
+	dir       string
+	urlPrefix string
+	secret    []byte
+	now       func() time.Time
+}
+
+var (
+	_ repository.Storage = (*Local)(nil)
+	_ http.Handler       = (*Local)(nil)
+)
+
+// NewLocal returns a Local storage in dir. Signed URLs start with urlPrefix and are signed with secret.
+func NewLocal(dir, urlPrefix, secret string) (*Local, error) {
+	if secret == "" {
+		return nil, errors.New("storage: secret of local storage is empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: failed creating %s: %w", dir, err)
+	}
+
+	return &Local{dir: dir, urlPrefix: urlPrefix, secret: []byte(secret), now: time.Now}, nil
+}
+
+// Put implements repository.Storage.
+// The file is written to a temporary file first, so readers never see partial files.
+func (l *Local) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	if !validKey(key) {
+		return errInvalidKey
+	}
+	p := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), p)
+}
+
+// Delete implements repository.Storage. Deleting a missing file is not an error.
+func (l *Local) Delete(_ context.Context, key string) error {
+	if !validKey(key) {
+		return errInvalidKey
+	}
+	if err := os.Remove(l.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// SignedURL implements repository.Storage
+func (l *Local) SignedURL(_ context.Context, key string, expires time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", errInvalidKey
+	}
+	exp := strconv.FormatInt(l.now().Add(expires).Unix(), 10)
+	q := url.Values{
+		"expires":   {exp},
+		"signature": {l.sign(key, exp)},
+	}
+
+	return l.urlPrefix + (&url.URL{Path: key}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+// ServeHTTP serves the file of the request path if the signature of the URL is valid and has not expired.
+// The path must not contain LocalPath, use http.StripPrefix.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	q := r.URL.Query()
+	exp := q.Get("expires")
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if !validKey(key) || err != nil ||
+		!hmac.Equal([]byte(q.Get("signature")), []byte(l.sign(key, exp))) ||
+		l.now().Unix() > expUnix {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	f, err := os.Open(l.path(key))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", expUnix-l.now().Unix()))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(key))
+}
+
+// sign returns the signature of the key valid until exp
+func (l *Local) sign(key, exp string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewLocal(t.TempDir(), LocalPath, "secret")
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.StripPrefix(LocalPath, l))
+	defer srv.Close()
+	require.NoError(t, l.Put(ctx, "users/usr_1/avatar.png", strings.NewReader("png"), 3, "image/png"))
+
+	get := func(t *testing.T, u string) (int, string) {
+		res, err := http.Get(srv.URL + u)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(b)
+	}
+
+	tests := []struct {
+		name   string
+		act    func(t *testing.T) string
+		assert func(t *testing.T, u string)
+	}{
+		{
+			name: "it should serve files with a valid signature",
+			act: func(t *testing.T) string {
+				u, err := l.SignedURL(ctx, "users/usr_1/avatar.png", time.Minute)
+				require.NoError(t, err)
+				return u
+			},
+			assert: func(t *testing.T, u string) {
+				status, body := get(t, u)
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "png", body)
+			},
+		},
+		{
+			name: "it should reject signatures of other files",
+			act: func(t *testing.T) string {
+				u, err := l.SignedURL(ctx, "users/usr_2/avatar.png", time.Minute)
+				require.NoError(t, err)
+				return strings.Replace(u, "usr_2", "usr_1", 1)
+			},
+			assert: func(t *testing.T, u string) {
+				status, _ := get(t, u)
+				assert.Equal(t, http.StatusForbidden, status)
+			},
+		},
+		{
+			name: "it should reject expired URLs",
+			act: func(t *testing.T) string {
+				u, err := l.SignedURL(ctx, "users/usr_1/avatar.png", -time.Minute)
+				require.NoError(t, err)
+				return u
+			},
+			assert: func(t *testing.T, u string) {
+				status, _ := get(t, u)
+				assert.Equal(t, http.StatusForbidden, status)
+			},
+		},
+		{
+			name: "it should not serve deleted files",
+			act: func(t *testing.T) string {
+				require.NoError(t, l.Put(ctx, "old.png", strings.NewReader("png"), 3, "image/png"))
+				require.NoError(t, l.Delete(ctx, "old.png"))
+				u, err := l.SignedURL(ctx, "old.png", time.Minute)
+				require.NoError(t, err)
+				return u
+			},
+			assert: func(t *testing.T, u string) {
+				status, _ := get(t, u)
+				assert.Equal(t, http.StatusNotFound, status)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := tt.act(t)
+			tt.assert(t, u)
+		})
+	}
+}
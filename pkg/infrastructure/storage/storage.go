@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/usercase/repository"
+)
+
+// Drivers of the file storage
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// LocalPath is the path under which the router serves the files of a Local storage
+const LocalPath = "/files/"
+
+// errInvalidKey is returned for keys which are empty or escape the storage, e.g. ../secret
+var errInvalidKey = errors.New("storage: invalid key")
+
+// New returns the storage configured in config.C.Storage
+func New() (repository.Storage, error) {
+	c := config.C.Storage
+	switch c.Driver {
+	case DriverLocal:
+		return NewLocal(c.Local.Dir, strings.TrimSuffix(c.Local.BaseURL, "/")+LocalPath, c.Local.Secret)
+	case DriverS3:
+		return NewS3(c.S3.Endpoint, c.S3.Region, c.S3.Bucket, c.S3.AccessKeyID, c.S3.SecretAccessKey, c.S3.PathStyle)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", c.Driver)
+	}
+}
+
+// validKey reports whether the key is a clean relative path
+func validKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, "/") && path.Clean(key) == key && key != ".." && !strings.HasPrefix(key, "../")
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/trustify/core/config"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInit(t *testing.T) {
+	type args struct {
+		exporter string
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantProvider bool
+		wantErr      string
+	}{
+		{
+			name:         "it should not install a provider without exporter",
+			args:         args{exporter: ExporterNone},
+			wantProvider: false,
+		},
+		{
+			name:         "it should install a provider for the stdout exporter",
+			args:         args{exporter: ExporterStdout},
+			wantProvider: true,
+		},
+		{
+			name:    "it should fail for an unknown exporter",
+			args:    args{exporter: "zipkin"},
+			wantErr: `unknown tracing exporter "zipkin"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			global := otel.GetTracerProvider()
+			defer otel.SetTracerProvider(global)
+			config.C.Tracing.Exporter = tt.args.exporter
+
+			shutdown, err := Init(context.Background())
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			assert.Equal(t, tt.wantProvider, ok)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestTracer(t *testing.T) {
+	global := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(global)
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+
+	_, span := Tracer().Start(context.Background(), "test")
+	span.End()
+
+	spans := sr.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "test", spans[0].Name())
+		assert.Equal(t, InstrumentationName, spans[0].InstrumentationLibrary().Name)
+	}
+}
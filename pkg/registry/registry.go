@@ -3,10 +3,12 @@ package registry
 import (
 	"gitlab.com/trustify/core/ent"
 	"gitlab.com/trustify/core/pkg/adapter/controller"
+	"gitlab.com/trustify/core/pkg/usercase/repository"
 )
 
 type registry struct {
-	client *ent.Client
+	client  *ent.Client
+	storage repository.Storage
 }
 
 // Registry is an interface of registry
@@ -15,9 +17,10 @@ type Registry interface {
 }
 
 // New registers entire controller with dependencies
-func New(client *ent.Client) Registry {
+func New(client *ent.Client, storage repository.Storage) Registry {
 	return &registry{
-		client: client,
+		client:  client,
+		storage: storage,
 	}
 }
 
@@ -1,14 +1,18 @@
 package registry
 
 import (
+	"gitlab.com/trustify/core/graph/generated"
 	"gitlab.com/trustify/core/pkg/adapter/controller"
+	"gitlab.com/trustify/core/pkg/adapter/directives"
 	"gitlab.com/trustify/core/pkg/adapter/repository"
 	"gitlab.com/trustify/core/pkg/usercase/usecase"
 )
 
 func (r *registry) NewUserController() controller.User {
 	repo := repository.NewUserRepository(r.client)
-	u := usecase.NewUserUsecase(repo)
+	// rows of imported files are validated with the rules of the input types in the schema
+	validator := directives.NewInputValidator(generated.NewExecutableSchema(generated.Config{}).Schema())
+	u := usecase.NewUserUsecase(repo, r.storage, validator, repository.NewTransaction())
 
 	return controller.NewUserController(u)
 }
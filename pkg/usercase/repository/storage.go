@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Storage stores uploaded files by key
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL granting read access to the file until it expires
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
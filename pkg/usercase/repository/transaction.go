@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// Transaction defers side effects of a usecase until the changes of the transaction of the operation are final
+type Transaction interface {
+	// OnCommit runs fn after the transaction in the context is committed, right away if there is none
+	OnCommit(ctx context.Context, fn func())
+	// OnRollback runs fn after the transaction in the context is rolled back, never if there is none
+	OnRollback(ctx context.Context, fn func())
+}
@@ -12,4 +12,11 @@ type User interface {
 	Create(ctx context.Context, input model.CreateUserInput) (*model.User, error)
 	Update(ctx context.Context, input model.UpdateUserInput) (*model.User, error)
 	EmailExists(ctx context.Context, email string) (bool, error)
+	ExistingEmails(ctx context.Context, emails []string, batchSize int) (map[string]bool, error)
+	UpdateAvatar(ctx context.Context, id model.ID, avatar string) (*model.User, error)
+	Search(ctx context.Context, query string, first int, after *model.Cursor) (*model.UserSearchConnection, error)
+	CreateBulk(ctx context.Context, inputs []model.CreateUserInput, batchSize int) ([]*model.User, error)
+	UpdateMany(ctx context.Context, where *model.UserWhereInput, set model.BulkUserPatch, max int) ([]*model.User, error)
+	Iterate(ctx context.Context, where *model.UserWhereInput, batchSize int, fn func(users []*model.User) error) error
+	Aggregate(ctx context.Context, where *model.UserWhereInput, groupBy []model.UserGroupField, interval *model.AggregateInterval) ([]*model.UserAggregate, error)
 }
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"gitlab.com/trustify/core/pkg/entity/model"
+)
+
+// Validator checks inputs which do not pass through the GraphQL layer against the rules of their input types
+type Validator interface {
+	// ValidateCreateUserInput returns the violated rules of CreateUserInput, none if the input is valid
+	ValidateCreateUserInput(ctx context.Context, input model.CreateUserInput) []*model.FieldError
+}
@@ -1,15 +1,33 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
 
+	"gitlab.com/trustify/core/config"
 	"gitlab.com/trustify/core/pkg/entity/model"
 	"gitlab.com/trustify/core/pkg/usercase/repository"
+	"gitlab.com/trustify/core/pkg/util/imaging"
+	"gitlab.com/trustify/core/pkg/util/metrics"
 )
 
+var usersCreated = metrics.NewCounter("users_created_total", "Number of created users")
+
+// defaultSearchPageSize is the number of users returned by a search if first is not given
+const defaultSearchPageSize = 10
+
 type user struct {
 	userRepository repository.User
+	storage        repository.Storage
+	validator      repository.Validator
+	transaction    repository.Transaction
 }
 
 // User of usercase
@@ -18,11 +36,19 @@ type User interface {
 	List(ctx context.Context, after *model.Cursor, first *int, before *model.Cursor, last *int, where *model.UserWhereInput) (*model.UserConnection, error)
 	Create(ctx context.Context, input model.CreateUserInput) (*model.User, error)
 	Update(ctx context.Context, input model.UpdateUserInput) (*model.User, error)
+	UploadAvatar(ctx context.Context, id model.ID, file model.Upload) (*model.User, error)
+	AvatarURL(ctx context.Context, u *model.User, thumbnail bool) (*string, error)
+	Search(ctx context.Context, query string, first *int, after *model.Cursor) (*model.UserSearchConnection, error)
+	Aggregate(ctx context.Context, where *model.UserWhereInput, groupBy []model.UserGroupField, interval *model.AggregateInterval) ([]*model.UserAggregate, error)
+	Export(ctx context.Context, where *model.UserWhereInput, fn func(users []*model.User) error) error
+	Import(ctx context.Context, r io.Reader, dryRun bool) (*model.ImportUsersReport, error)
+	CreateMany(ctx context.Context, inputs []*model.CreateUserInput) ([]*model.User, error)
+	UpdateMany(ctx context.Context, where *model.UserWhereInput, set model.BulkUserPatch) ([]*model.User, error)
 }
 
 // NewUserUsecase returns user usecse
-func NewUserUsecase(r repository.User) User {
-	return &user{userRepository: r}
+func NewUserUsecase(r repository.User, s repository.Storage, v repository.Validator, t repository.Transaction) User {
+	return &user{userRepository: r, storage: s, validator: v, transaction: t}
 }
 
 func (u *user) Get(ctx context.Context, id *model.ID) (*model.User, error) {
@@ -40,12 +66,194 @@ func (u *user) Create(ctx context.Context, input model.CreateUserInput) (*model.
 	}
 
 	if ex {
-		return nil, errors.New("user with the given email already exists")
+		return nil, model.NewConflictError(nil, "user with the given email already exists")
+	}
+
+	created, err := u.userRepository.Create(ctx, input)
+	if err != nil {
+		return nil, err
 	}
+	// the operation may still be rolled back, so only committed users are counted
+	u.transaction.OnCommit(ctx, usersCreated.WithLabelValues().Inc)
 
-	return u.userRepository.Create(ctx, input)
+	return created, nil
 }
 
 func (u *user) Update(ctx context.Context, input model.UpdateUserInput) (*model.User, error) {
 	return u.userRepository.Update(ctx, input)
 }
+
+// CreateMany creates every user or none. Emails must be unique among the inputs and the existing users
+// ignoring case, violations are returned for every input in a model.BulkError.
+func (u *user) CreateMany(ctx context.Context, inputs []*model.CreateUserInput) ([]*model.User, error) {
+	if len(inputs) > config.C.GraphQL.MaxBulkSize {
+		return nil, model.NewValidationError(fmt.Errorf("%d inputs", len(inputs)), "too many inputs in bulk mutation")
+	}
+
+	emails := make([]string, len(inputs))
+	for i, input := range inputs {
+		emails[i] = input.Email
+	}
+	existing, err := u.userRepository.ExistingEmails(ctx, emails, config.C.GraphQL.MaxBulkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// emails are compared case-insensitively, like by the repository
+	bulkErr := &model.BulkError{}
+	seen := make(map[string]bool, len(inputs))
+	values := make([]model.CreateUserInput, len(inputs))
+	for i, input := range inputs {
+		values[i] = *input
+		key := strings.ToLower(input.Email)
+		if seen[key] {
+			bulkErr.Errors = append(bulkErr.Errors, &model.ItemError{Index: i, Field: "email", Err: model.NewConflictError(nil, "email appears more than once in the inputs")})
+			continue
+		}
+		seen[key] = true
+		if existing[key] {
+			bulkErr.Errors = append(bulkErr.Errors, &model.ItemError{Index: i, Field: "email", Err: model.NewConflictError(nil, "user with the given email already exists")})
+		}
+	}
+	if len(bulkErr.Errors) > 0 {
+		return nil, bulkErr
+	}
+
+	created, err := u.userRepository.CreateBulk(ctx, values, config.C.GraphQL.MaxBulkSize)
+	if err != nil {
+		return nil, err
+	}
+	u.transaction.OnCommit(ctx, func() { usersCreated.WithLabelValues().Add(float64(len(created))) })
+
+	return created, nil
+}
+
+// UpdateMany sets the fields of the patch on every user matching where,
+// at most config.C.GraphQL.MaxBulkSize users are updated at once
+func (u *user) UpdateMany(ctx context.Context, where *model.UserWhereInput, set model.BulkUserPatch) ([]*model.User, error) {
+	if set.IsEmpty() {
+		return nil, model.NewValidationError(nil, "set must change at least one field")
+	}
+
+	return u.userRepository.UpdateMany(ctx, where, set, config.C.GraphQL.MaxBulkSize)
+}
+
+// Search returns a page of the users matching the query, at most config.C.Search.MaxPageSize at once
+func (u *user) Search(ctx context.Context, query string, first *int, after *model.Cursor) (*model.UserSearchConnection, error) {
+	n := defaultSearchPageSize
+	if first != nil {
+		n = *first
+	}
+	if n < 0 || n > config.C.Search.MaxPageSize {
+		return nil, model.NewValidationError(fmt.Errorf("first is %d", n), "first must be between 0 and the maximum page size")
+	}
+
+	return u.userRepository.Search(ctx, query, n, after)
+}
+
+// Aggregate counts the users matching where per group and per creation interval
+func (u *user) Aggregate(ctx context.Context, where *model.UserWhereInput, groupBy []model.UserGroupField, interval *model.AggregateInterval) ([]*model.UserAggregate, error) {
+	return u.userRepository.Aggregate(ctx, where, groupBy, interval)
+}
+
+// Export calls fn with consecutive batches of the users matching where, ordered by id.
+// Passwords are not loaded.
+func (u *user) Export(ctx context.Context, where *model.UserWhereInput, fn func(users []*model.User) error) error {
+	return u.userRepository.Iterate(ctx, where, config.C.Export.BatchSize, fn)
+}
+
+// UploadAvatar stores the image and its thumbnail and replaces the previous avatar of the user
+func (u *user) UploadAvatar(ctx context.Context, id model.ID, file model.Upload) (*model.User, error) {
+	current, err := u.userRepository.Get(ctx, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := config.C.Avatar.MaxSize
+	if file.Size > maxSize {
+		return nil, model.NewValidationError(fmt.Errorf("%d bytes exceed the limit of %d", file.Size, maxSize), "avatar is too large")
+	}
+	data, err := io.ReadAll(io.LimitReader(file.File, maxSize+1))
+	if err != nil {
+		return nil, model.NewInternalError(err, "failed to read avatar")
+	}
+	if int64(len(data)) > maxSize {
+		return nil, model.NewValidationError(fmt.Errorf("avatar exceeds the limit of %d bytes", maxSize), "avatar is too large")
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, model.NewValidationError(err, "avatar must be a JPEG, PNG or GIF image")
+	}
+	thumbnail, err := imaging.EncodePNG(imaging.Thumbnail(img, config.C.Avatar.ThumbnailSize))
+	if err != nil {
+		return nil, model.NewInternalError(err, "failed to store avatar")
+	}
+
+	key, err := avatarKey(id, img.Format)
+	if err != nil {
+		return nil, model.NewInternalError(err, "failed to store avatar")
+	}
+	if err := u.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), img.ContentType); err != nil {
+		return nil, model.NewInternalError(err, "failed to store avatar")
+	}
+	if err := u.storage.Put(ctx, thumbnailKey(key), bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/png"); err != nil {
+		u.deleteAvatar(ctx, key)
+		return nil, model.NewInternalError(err, "failed to store avatar")
+	}
+
+	updated, err := u.userRepository.UpdateAvatar(ctx, id, key)
+	if err != nil {
+		u.deleteAvatar(ctx, key)
+		return nil, err
+	}
+	// the avatar is set in the transaction of the operation, so the user keeps the previous one until it is committed
+	u.transaction.OnRollback(ctx, func() { u.deleteAvatar(context.WithoutCancel(ctx), key) })
+	if current.Avatar != nil {
+		previous := *current.Avatar
+		u.transaction.OnCommit(ctx, func() { u.deleteAvatar(context.WithoutCancel(ctx), previous) })
+	}
+
+	return updated, nil
+}
+
+// AvatarURL returns a signed URL of the avatar or its thumbnail, nil if the user has no avatar
+func (u *user) AvatarURL(ctx context.Context, usr *model.User, thumbnail bool) (*string, error) {
+	if usr.Avatar == nil {
+		return nil, nil
+	}
+	key := *usr.Avatar
+	if thumbnail {
+		key = thumbnailKey(key)
+	}
+
+	url, err := u.storage.SignedURL(ctx, key, config.C.Storage.SignedURLExpiry)
+	if err != nil {
+		return nil, model.NewInternalError(err, "failed to sign avatar url")
+	}
+	return &url, nil
+}
+
+// deleteAvatar removes the avatar and its thumbnail. Failures only leave unused files behind, so they are logged.
+func (u *user) deleteAvatar(ctx context.Context, key string) {
+	for _, k := range []string{key, thumbnailKey(key)} {
+		if err := u.storage.Delete(ctx, k); err != nil {
+			slog.WarnContext(ctx, "failed deleting avatar", slog.String("key", k), slog.Any("error", err))
+		}
+	}
+}
+
+// avatarKey returns a new storage key of an avatar of the user.
+// Every upload gets a new key, so signed URLs of the previous avatar never return the new one.
+func avatarKey(id model.ID, format string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed generating avatar key: %w", err)
+	}
+	return fmt.Sprintf("avatars/%s/%s.%s", id, hex.EncodeToString(b), format), nil
+}
+
+// thumbnailKey returns the storage key of the thumbnail of the avatar
+func thumbnailKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_thumb.png"
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/entity/model"
+	"gitlab.com/trustify/core/pkg/util/i18n"
+)
+
+// Columns of imported files, matched case-insensitively. Other columns are ignored.
+const (
+	importColumnFirstName = "firstName"
+	importColumnLastName  = "lastName"
+	importColumnEmail     = "email"
+	importColumnPassword  = "password"
+)
+
+var importColumns = []string{importColumnFirstName, importColumnLastName, importColumnEmail, importColumnPassword}
+
+// importRow is a data row of an imported file
+type importRow struct {
+	line  int
+	input model.CreateUserInput
+	// err is set if the row could not be read as a user
+	err error
+}
+
+// Import creates a user for every row of the CSV file.
+// Every row is validated like CreateUserInput and its email must be unique in the file and among the existing users,
+// ignoring case.
+// Users are only created if no row has errors and dryRun is false.
+func (u *user) Import(ctx context.Context, r io.Reader, dryRun bool) (*model.ImportUsersReport, error) {
+	rows, err := readImportRows(r, config.C.Import.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+	report := &model.ImportUsersReport{DryRun: dryRun, Rows: len(rows), Errors: []*model.ImportUserError{}}
+	addError := func(line int, field string, message string) {
+		e := &model.ImportUserError{Row: line, Message: message}
+		if field != "" {
+			e.Field = &field
+		}
+		report.Errors = append(report.Errors, e)
+	}
+
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.err == nil {
+			emails = append(emails, row.input.Email)
+		}
+	}
+	existing, err := u.userRepository.ExistingEmails(ctx, emails, config.C.Import.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// emails are compared case-insensitively, like by the repository
+	seen := make(map[string]bool, len(rows))
+	inputs := make([]model.CreateUserInput, 0, len(rows))
+	for _, row := range rows {
+		if row.err != nil {
+			addError(row.line, "", i18n.Translate(locale, row.err.Error()))
+			continue
+		}
+		valid := true
+		for _, fe := range u.validator.ValidateCreateUserInput(ctx, row.input) {
+			addError(row.line, fe.Field, fe.Message)
+			if fe.Field == importColumnEmail {
+				valid = false
+			}
+		}
+		if valid {
+			key := strings.ToLower(row.input.Email)
+			if seen[key] {
+				addError(row.line, importColumnEmail, i18n.Translate(locale, "email appears more than once in the file"))
+			} else {
+				seen[key] = true
+				if existing[key] {
+					addError(row.line, importColumnEmail, i18n.Translate(locale, "user with the given email already exists"))
+				}
+			}
+		}
+		inputs = append(inputs, row.input)
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	created, err := u.userRepository.CreateBulk(ctx, inputs, config.C.Import.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = len(created)
+	u.transaction.OnCommit(ctx, func() { usersCreated.WithLabelValues().Add(float64(report.Imported)) })
+
+	return report, nil
+}
+
+// readImportRows reads the data rows of a CSV file with a header row.
+// Rows with a wrong number of columns are returned with an error, other syntax errors fail the whole file.
+func readImportRows(r io.Reader, maxRows int) ([]*importRow, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, model.NewValidationError(err, "csv file could not be parsed")
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		// spreadsheet applications may start the file with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		for _, c := range importColumns {
+			if strings.EqualFold(strings.TrimSpace(name), c) {
+				index[c] = i
+			}
+		}
+	}
+	if len(index) != len(importColumns) {
+		return nil, model.NewValidationError(fmt.Errorf("header is %v", header), "csv file must have the columns firstName, lastName, email and password")
+	}
+
+	var rows []*importRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if len(rows) == maxRows {
+			return nil, model.NewValidationError(fmt.Errorf("more than %d rows", maxRows), "csv file has too many rows")
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			rows = append(rows, &importRow{line: parseErr.StartLine, err: errors.New("row must have as many columns as the header")})
+			continue
+		}
+		if err != nil {
+			return nil, model.NewValidationError(err, "csv file could not be parsed")
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, &importRow{line: line, input: model.CreateUserInput{
+			FirstName: strings.TrimSpace(record[index[importColumnFirstName]]),
+			LastName:  strings.TrimSpace(record[index[importColumnLastName]]),
+			Email:     strings.TrimSpace(record[index[importColumnEmail]]),
+			Password:  record[index[importColumnPassword]],
+		}})
+	}
+}
@@ -1,8 +1,109 @@
 package datetime
 
-import "time"
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
 
-// FormatDate returns time formatted as RFC3339 (2020-11-10T13:28:12+09:00)
-func FormatDate(t time.Time) string {
-	return t.Format(time.RFC3339)
+	// Embed the timezone database so that timezone arguments work without tzdata installed
+	_ "time/tzdata"
+)
+
+// Format of a DateTime
+type Format string
+
+// Supported formats
+const (
+	// RFC3339Nano is the default format and can be parsed as DateTime (2020-11-10T13:28:12.123456789+09:00)
+	RFC3339Nano Format = "RFC3339_NANO"
+	// RFC3339 drops fractional seconds (2020-11-10T13:28:12+09:00)
+	RFC3339 Format = "RFC3339"
+	// Date only contains the calendar date (2020-11-10)
+	Date Format = "DATE"
+)
+
+var layouts = map[Format]string{
+	RFC3339Nano: time.RFC3339Nano,
+	RFC3339:     time.RFC3339,
+	Date:        "2006-01-02",
+}
+
+// DateTime is a point in time which is marshaled with the given format
+type DateTime struct {
+	time.Time
+	Format Format
+}
+
+// New returns t as DateTime in the given timezone and format.
+// A nil timezone keeps the location of t, a nil format defaults to RFC3339Nano.
+func New(t time.Time, timezone *string, format *Format) (*DateTime, error) {
+	dt := &DateTime{Time: t, Format: RFC3339Nano}
+	if format != nil {
+		dt.Format = *format
+	}
+	if timezone != nil {
+		loc, err := time.LoadLocation(*timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", *timezone, err)
+		}
+		dt.Time = t.In(loc)
+	}
+
+	return dt, nil
+}
+
+// String returns the formatted time
+func (d DateTime) String() string {
+	layout, ok := layouts[d.Format]
+	if !ok {
+		layout = time.RFC3339Nano
+	}
+	return d.Time.Format(layout)
+}
+
+// MarshalGQL implements the graphql.Marshaler interface
+func (d DateTime) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(d.String()))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface.
+// RFC3339 timestamps with and without fractional seconds are accepted.
+func (d *DateTime) UnmarshalGQL(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("datetime: expected an RFC3339 formatted string %v", v)
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("datetime: %w", err)
+	}
+	*d = DateTime{Time: t, Format: RFC3339Nano}
+
+	return nil
+}
+
+// IsValid reports whether f is a supported format
+func (f Format) IsValid() bool {
+	_, ok := layouts[f]
+	return ok
+}
+
+// MarshalGQL implements the graphql.Marshaler interface
+func (f Format) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(f)))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface
+func (f *Format) UnmarshalGQL(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("datetime: format must be a string %v", v)
+	}
+	*f = Format(s)
+	if !f.IsValid() {
+		return fmt.Errorf("datetime: %s is not a valid DateTimeFormat", s)
+	}
+
+	return nil
 }
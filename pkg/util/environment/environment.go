@@ -1,7 +1,6 @@
 package environment
 
 import (
-	"log"
 	"os"
 )
 
@@ -14,18 +13,20 @@ const (
 
 // IsDev returns APP_ENV in development mode
 func IsDev() bool {
-	log.Println("APP_ENV: ", os.Getenv("APP_ENV"))
 	return os.Getenv("APP_ENV") == Development
 }
 
 // IsDev returns APP_ENV in test mode
 func IsTest() bool {
-	log.Println("APP_ENV: ", os.Getenv("APP_ENV"))
 	return os.Getenv("APP_ENV") == Test
 }
 
 // IsDev returns APP_ENV in e2e mode
 func IsE2E() bool {
-	log.Println("APP_ENV: ", os.Getenv("APP_ENV"))
 	return os.Getenv("APP_ENV") == E2E
 }
+
+// IsProd returns true if APP_ENV is none of the known non-production modes
+func IsProd() bool {
+	return !IsDev() && !IsTest() && !IsE2E()
+}
@@ -0,0 +1,134 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultLocale is used if no supported locale could be negotiated
+const DefaultLocale = "en"
+
+//go:embed locales/*.yml
+var files embed.FS
+
+// catalog holds the messages of a single locale
+type catalog struct {
+	// Messages are keyed by their english text
+	Messages map[string]string `yaml:"messages"`
+	// Validation messages are keyed by validator tag
+	Validation map[string]string `yaml:"validation"`
+}
+
+var (
+	catalogs = mustLoad()
+	locales  = sortedLocales(catalogs)
+	matcher  = newMatcher(locales)
+)
+
+type localeKey struct{}
+
+// Locales returns every supported locale, starting with the default locale
+func Locales() []string {
+	return append([]string(nil), locales...)
+}
+
+// Translate returns the message in the given locale.
+// The message itself is returned if there is no translation.
+func Translate(locale string, message string) string {
+	if c, ok := catalogs[locale]; ok {
+		if t, ok := c.Messages[message]; ok {
+			return t
+		}
+	}
+	return message
+}
+
+// ValidationMessages returns the validation messages of a locale keyed by validator tag
+func ValidationMessages(locale string) map[string]string {
+	return catalogs[locale].Validation
+}
+
+// Negotiate returns the supported locale matching an Accept-Language header best
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, i, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return locales[i]
+}
+
+// WithLocale returns a context carrying the locale used to render messages.
+// The locale is negotiated from Accept-Language only, there is no locale stored for users
+// since requests are not authenticated as a user.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale of the context or the default locale
+func LocaleFromContext(ctx context.Context) string {
+	if l, ok := ctx.Value(localeKey{}).(string); ok {
+		return l
+	}
+	return DefaultLocale
+}
+
+func mustLoad() map[string]catalog {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		log.Fatalf("could not read message files: %v", err)
+	}
+
+	result := make(map[string]catalog, len(entries))
+	for _, e := range entries {
+		b, err := files.ReadFile(path.Join("locales", e.Name()))
+		if err != nil {
+			log.Fatalf("could not read message file %s: %v", e.Name(), err)
+		}
+		var c catalog
+		if err := yaml.Unmarshal(b, &c); err != nil {
+			log.Fatalf("could not parse message file %s: %v", e.Name(), err)
+		}
+		result[strings.TrimSuffix(e.Name(), path.Ext(e.Name()))] = c
+	}
+
+	if _, ok := result[DefaultLocale]; !ok {
+		log.Fatalf("missing message file for default locale %s", DefaultLocale)
+	}
+
+	return result
+}
+
+func sortedLocales(c map[string]catalog) []string {
+	result := make([]string, 0, len(c))
+	for l := range c {
+		if l != DefaultLocale {
+			result = append(result, l)
+		}
+	}
+	sort.Strings(result)
+
+	return append([]string{DefaultLocale}, result...)
+}
+
+func newMatcher(locales []string) language.Matcher {
+	tags := make([]language.Tag, len(locales))
+	for i, l := range locales {
+		t, err := language.Parse(l)
+		if err != nil {
+			log.Fatalf("invalid locale %s: %v", l, err)
+		}
+		tags[i] = t
+	}
+	return language.NewMatcher(tags)
+}
@@ -0,0 +1,119 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	// register the decoders of the accepted formats
+	_ "image/gif"
+	_ "image/jpeg"
+)
+
+// maxPixels limits the dimensions of decoded images, small files may decode to huge images
+const maxPixels = 25_000_000
+
+// contentTypes of the accepted formats
+var contentTypes = map[string]string{
+	"gif":  "image/gif",
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+}
+
+// ErrUnsupported is returned for data which is not an image of an accepted format
+var ErrUnsupported = errors.New("imaging: unsupported image")
+
+// Image is a decoded image with its format
+type Image struct {
+	image.Image
+	// Format is gif, jpeg or png
+	Format      string
+	ContentType string
+}
+
+// Decode decodes a GIF, JPEG or PNG image
+func Decode(data []byte) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: format %s", ErrUnsupported, format)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels exceed the limit", ErrUnsupported, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+
+	return &Image{Image: img, Format: format, ContentType: contentType}, nil
+}
+
+// Thumbnail crops the center square of the image and scales it to size x size pixels.
+// Every pixel of the thumbnail is the average of the pixels it covers in the image.
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	thumb := image.NewNRGBA(image.Rect(0, 0, size, size))
+	if side == 0 {
+		return thumb
+	}
+	for ty := 0; ty < size; ty++ {
+		sy0, sy1 := span(ty, size, side)
+		for tx := 0; tx < size; tx++ {
+			sx0, sx1 := span(tx, size, side)
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					c := color.NRGBA64Model.Convert(img.At(x0+sx, y0+sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			thumb.SetNRGBA(tx, ty, color.NRGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return thumb
+}
+
+// span returns the source pixels [from, to) covered by the target pixel i, at least one pixel
+func span(i, size, side int) (int, int) {
+	from := i * side / size
+	to := (i + 1) * side / size
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
+
+// EncodePNG encodes the image as PNG
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
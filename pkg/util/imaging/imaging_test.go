@@ -0,0 +1,69 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/trustify/core/pkg/util/imaging"
+)
+
+func TestDecode(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2))))
+
+	tests := []struct {
+		name   string
+		data   []byte
+		assert func(t *testing.T, got *imaging.Image, err error)
+	}{
+		{
+			name: "it should decode PNG images",
+			data: buf.Bytes(),
+			assert: func(t *testing.T, got *imaging.Image, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "png", got.Format)
+				assert.Equal(t, "image/png", got.ContentType)
+				assert.Equal(t, image.Rect(0, 0, 4, 2), got.Bounds())
+			},
+		},
+		{
+			name: "it should reject other data",
+			data: []byte("<svg></svg>"),
+			assert: func(t *testing.T, got *imaging.Image, err error) {
+				assert.ErrorIs(t, err, imaging.ErrUnsupported)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := imaging.Decode(tt.data)
+			tt.assert(t, got, err)
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// 4x2 image with a red left and a blue right half
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	got := imaging.Thumbnail(img, 1)
+
+	assert.Equal(t, image.Rect(0, 0, 1, 1), got.Bounds())
+	// the center square covers one red and one blue column
+	assert.Equal(t, color.NRGBA{R: 127, B: 127, A: 255}, got.At(0, 0))
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/pkg/util/environment"
+)
+
+// redacted replaces the value of sensitive fields
+const redacted = "[REDACTED]"
+
+// sensitiveFields are parts of field names whose values must not be logged
+var sensitiveFields = []string{"password", "token", "secret", "authorization"}
+
+type requestIDKey struct{}
+
+// Init installs the default logger with the level of config.C.Log.
+// Logs are written as text in development and as JSON otherwise.
+// The standard log package writes to the default logger as well.
+func Init() {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.C.Log.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if environment.IsDev() {
+		h = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		h = slog.NewJSONHandler(os.Stdout, opts)
+	}
+
+	slog.SetDefault(slog.New(contextHandler{h}))
+}
+
+// WithRequestID returns a context carrying the id of the request, which is added to every log record of the request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the id of the request or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Redact returns a copy of v in which the values of sensitive fields are replaced, e.g. the password of an input.
+// Maps and slices are redacted recursively.
+func Redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			if IsSensitive(k) {
+				result[k] = redacted
+			} else {
+				result[k] = Redact(v)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(t))
+		for i, v := range t {
+			result[i] = Redact(v)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// IsSensitive reports whether the value of the field must not be logged
+func IsSensitive(field string) bool {
+	f := strings.ToLower(field)
+	for _, s := range sensitiveFields {
+		if strings.Contains(f, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler adds the request id of the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,59 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/trustify/core/pkg/util/logger"
+)
+
+func TestRedact(t *testing.T) {
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name string
+		args args
+		want interface{}
+	}{
+		{
+			name: "it should redact sensitive fields of nested inputs",
+			args: args{v: map[string]interface{}{
+				"input": map[string]interface{}{
+					"email":       "john@yourname.xyz",
+					"password":    "secret1234",
+					"newPassword": "secret5678",
+				},
+			}},
+			want: map[string]interface{}{
+				"input": map[string]interface{}{
+					"email":       "john@yourname.xyz",
+					"password":    "[REDACTED]",
+					"newPassword": "[REDACTED]",
+				},
+			},
+		},
+		{
+			name: "it should redact sensitive fields in lists",
+			args: args{v: []interface{}{
+				map[string]interface{}{"accessToken": "abc", "id": "1"},
+			}},
+			want: []interface{}{
+				map[string]interface{}{"accessToken": "[REDACTED]", "id": "1"},
+			},
+		},
+		{
+			name: "it should keep scalars",
+			args: args{v: "secret1234"},
+			want: "secret1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := logger.Redact(tt.args.v)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
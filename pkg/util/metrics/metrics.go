@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the name of every metric of this service
+const Namespace = "trustify"
+
+// registry holds the metrics of this service and the metrics of the Go runtime and the process
+var registry = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return r
+}
+
+// Handler returns the handler exposing the metrics in the prometheus format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// NewCounter registers a counter, e.g. a business metric like the number of users created.
+// It panics if a metric with the same name is already registered, so counters should be package level variables.
+func NewCounter(name string, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	registry.MustRegister(c)
+
+	return c
+}
+
+// NewHistogram registers a histogram with the default buckets of prometheus, which fit latencies in seconds.
+// It panics if a metric with the same name is already registered.
+func NewHistogram(name string, help string, labels ...string) *prometheus.HistogramVec {
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      name,
+		Help:      help,
+		Buckets:   prometheus.DefBuckets,
+	}, labels)
+	registry.MustRegister(h)
+
+	return h
+}
+
+// Register registers a custom collector, e.g. the connection pool statistics of a database.
+// A collector collecting the same metrics as an already registered collector is ignored.
+func Register(c prometheus.Collector) error {
+	err := registry.Register(c)
+	if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,142 @@
+package export_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestExport_Users(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	// smaller than the number of users so that several batches are streamed
+	batchSize := config.C.Export.BatchSize
+	config.C.Export.BatchSize = 2
+	defer func() { config.C.Export.BatchSize = batchSize }()
+	token := "Bearer " + config.C.Export.Tokens[0]
+
+	arrange := func(t *testing.T) {
+		ctx := context.Background()
+		for _, u := range []struct{ first, email string }{
+			{"John", "john@yourname.xyz"},
+			{"Jack", "jack@yourname.xyz"},
+			{"Jane", "jane@yourname.xyz"},
+		} {
+			_, err := client.User.Create().
+				SetFirstName(u.first).
+				SetLastName("Doe").
+				SetEmail(u.email).
+				SetPassword("secret1234").
+				Save(ctx)
+			if err != nil {
+				t.Error(err)
+				t.FailNow()
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		arrange  func(t *testing.T)
+		act      func(t *testing.T) *httpexpect.Response
+		assert   func(t *testing.T, got *httpexpect.Response)
+		teardown func(t *testing.T)
+	}{
+		{
+			name:    "it should stream all users as csv without passwords",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.ExportPath).WithHeader("Authorization", token).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.Header("Content-Type").Equal("text/csv; charset=utf-8")
+				body := got.Body().Raw()
+				lines := strings.Split(strings.TrimSpace(body), "\n")
+				if len(lines) != 4 {
+					t.Fatalf("expected a header and 3 users, got %q", body)
+				}
+				got.Body().NotContains("secret1234")
+				if lines[0] != "id,firstName,lastName,email,createdAt,updatedAt" {
+					t.Errorf("unexpected header %q", lines[0])
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should stream the users matching the filter as ndjson",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.ExportPath).
+					WithHeader("Authorization", token).
+					WithQuery("format", "ndjson").
+					WithQuery("where", `{"firstNameHasPrefix": "Ja"}`).
+					Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.Header("Content-Type").Equal("application/x-ndjson")
+				lines := strings.Split(strings.TrimSpace(got.Body().Raw()), "\n")
+				if len(lines) != 2 {
+					t.Fatalf("expected 2 users, got %q", lines)
+				}
+				got.Body().Contains(`"firstName":"Jack"`)
+				got.Body().Contains(`"firstName":"Jane"`)
+				got.Body().NotContains("password")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should reject requests without a valid token",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.ExportPath).WithHeader("Authorization", "Bearer invalid").Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusUnauthorized)
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name:    "it should reject unknown formats",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.ExportPath).
+					WithHeader("Authorization", token).
+					WithQuery("format", "xml").
+					Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusBadRequest)
+				got.JSON().Object().ValueEqual("message", "format must be csv or ndjson")
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
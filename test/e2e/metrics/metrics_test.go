@@ -0,0 +1,60 @@
+package metrics_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestMetrics(t *testing.T) {
+	expect, _, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T)
+		act     func(t *testing.T) *httpexpect.Response
+		assert  func(t *testing.T, got *httpexpect.Response)
+	}{
+		{
+			name: "it should expose the metrics of executed operations and errors",
+			arrange: func(t *testing.T) {
+				expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `query MetricsUsers { users { totalCount } }`,
+				}).Expect().Status(http.StatusOK)
+				expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `query MetricsNode { node(id: "invalid") { id } }`,
+				}).Expect().Status(http.StatusOK)
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.MetricsPath).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				body := got.Body()
+				body.Contains(`trustify_graphql_operations_total{operation="MetricsUsers",type="query"} 1`)
+				body.Contains(`trustify_graphql_operations_total{operation="other",type="query"} 1`)
+				body.NotContains(`operation="MetricsNode"`)
+				body.Contains(`trustify_graphql_field_duration_seconds_count{field="users",object="Query"}`)
+				body.Contains(`trustify_graphql_errors_total{code=`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package mutation_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestUser_UploadAvatar(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	var avatar bytes.Buffer
+	if err := png.Encode(&avatar, image.NewRGBA(image.Rect(0, 0, 300, 200))); err != nil {
+		t.Fatal(err)
+	}
+
+	var id, previous string
+	upload := func(name string, file []byte) *httpexpect.Response {
+		return expect.POST(router.QueryPath).WithMultipart().
+			WithFormField("operations", `{
+				"query": "mutation ($id: ID!, $file: Upload!) { uploadAvatar(id: $id, file: $file) { user { id avatarUrl thumbnail: avatarUrl(thumbnail: true) } userErrors { field code message } } }",
+				"variables": {"id": "`+id+`", "file": null}
+			}`).
+			WithFormField("map", `{"0": ["variables.file"]}`).
+			WithFileBytes("0", name, file).
+			Expect()
+	}
+
+	tests := []struct {
+		name     string
+		arrange  func(t *testing.T)
+		act      func(t *testing.T) *httpexpect.Response
+		assert   func(t *testing.T, got *httpexpect.Response)
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should store the avatar and its thumbnail",
+			arrange: func(t *testing.T) {
+				u, err := client.User.Create().
+					SetFirstName("John").
+					SetLastName("Doe").
+					SetEmail("john@yourname.xyz").
+					SetPassword("secret1234").
+					Save(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				id = string(u.ID)
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return upload("avatar.png", avatar.Bytes())
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				res := e2e.GetData(got).Object()
+				user := e2e.GetObject(res, "uploadAvatar.user")
+
+				original := expect.GET(user.Value("avatarUrl").String().Raw()).Expect()
+				original.Status(http.StatusOK)
+				original.Body().Equal(avatar.String())
+
+				thumbnail := expect.GET(user.Value("thumbnail").String().Raw()).Expect()
+				thumbnail.Status(http.StatusOK)
+				thumb, err := png.Decode(bytes.NewReader([]byte(thumbnail.Body().Raw())))
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				if b := thumb.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+					t.Errorf("got thumbnail of %dx%d, want 128x128", b.Dx(), b.Dy())
+				}
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name: "it should delete the previous avatar once it is replaced",
+			arrange: func(t *testing.T) {
+				got := upload("avatar.png", avatar.Bytes())
+				got.Status(http.StatusOK)
+				previous = e2e.GetData(got).Path("$.uploadAvatar.user.avatarUrl").String().Raw()
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return upload("avatar.png", avatar.Bytes())
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				current := e2e.GetData(got).Path("$.uploadAvatar.user.avatarUrl").String().Raw()
+				expect.GET(current).Expect().Status(http.StatusOK)
+				expect.GET(previous).Expect().Status(http.StatusNotFound)
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name:    "it should reject files which are not images",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return upload("avatar.svg", []byte("<svg></svg>"))
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.JSON().Path("$.data.uploadAvatar.user").Null()
+				got.JSON().Path("$.data.uploadAvatar.userErrors[0].code").String().Equal("VALIDATION")
+				got.JSON().Path("$.data.uploadAvatar.userErrors[0].message").String().Equal("avatar must be a JPEG, PNG or GIF image")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
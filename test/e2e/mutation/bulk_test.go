@@ -0,0 +1,221 @@
+package mutation_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/user"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestUser_BulkMutations(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	countUsers := func(t *testing.T) int {
+		n, err := client.User.Query().Count(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	createUser := func(t *testing.T, firstName, email string) {
+		_, err := client.User.Create().
+			SetFirstName(firstName).
+			SetLastName("Doe").
+			SetEmail(email).
+			SetPassword("secret1234").
+			Save(context.Background())
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+	}
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T)
+		act     func(t *testing.T) *httpexpect.Response
+		assert  func(t *testing.T, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name:    "it should create every user",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `
+						mutation {
+							createUsers(inputs: [
+								{firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret12345"},
+								{firstName: "Jack", lastName: "Sparrow", email: "jack@yourname.xyz", password: "secret12345"}
+							]) {
+								users {
+									firstName
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				users := e2e.GetData(got).Path("$.createUsers.users").Array()
+				users.Length().Equal(2)
+				users.Element(0).Object().ValueEqual("firstName", "John")
+				users.Element(1).Object().ValueEqual("firstName", "Jack")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should report the errors of every input and create no user",
+			arrange: func(t *testing.T) {
+				createUser(t, "Jane", "jane@yourname.xyz")
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `
+						mutation {
+							createUsers(inputs: [
+								{firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret12345"},
+								{firstName: "Jane", lastName: "Doe", email: "jane@yourname.xyz", password: "secret12345"},
+								{firstName: "Johnny", lastName: "Doe", email: "john@yourname.xyz", password: "secret12345"}
+							]) {
+								users {
+									id
+								}
+								userErrors {
+									field
+									code
+									message
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				payload := e2e.GetData(got).Path("$.createUsers").Object()
+				payload.Value("users").Null()
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(2)
+				errors.Element(0).Object().Value("field").Equal([]string{"inputs", "1", "email"})
+				errors.Element(0).Object().Value("code").Equal("CONFLICT")
+				errors.Element(1).Object().Value("field").Equal([]string{"inputs", "2", "email"})
+				errors.Element(1).Object().Value("message").Equal("email appears more than once in the inputs")
+				if n := countUsers(t); n != 1 {
+					t.Errorf("expected only the existing user, got %d", n)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should validate every input",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `
+						mutation {
+							createUsers(inputs: [
+								{firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret12345"},
+								{firstName: "Jack", lastName: "Sparrow", email: "jack@yourname.xyz", password: "jacksecret"}
+							]) {
+								users {
+									id
+								}
+								userErrors {
+									field
+									code
+									message
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetData(got).Path("$.createUsers.userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("field").Equal([]string{"inputs", "1", "password"})
+				errors.First().Object().Value("code").Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name: "it should update every user matching the filter",
+			arrange: func(t *testing.T) {
+				createUser(t, "John", "john@yourname.xyz")
+				createUser(t, "Jack", "jack@yourname.xyz")
+				createUser(t, "Jane", "jane@example.com")
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `
+						mutation {
+							updateUsers(where: {emailHasSuffix: "@yourname.xyz"}, set: {lastName: "Smith"}) {
+								users {
+									firstName
+									lastName
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				users := e2e.GetData(got).Path("$.updateUsers.users").Array()
+				users.Length().Equal(2)
+				users.Element(0).Object().ValueEqual("lastName", "Smith")
+				users.Element(1).Object().ValueEqual("lastName", "Smith")
+				n, err := client.User.Query().Where(user.LastName("Doe")).Count(context.Background())
+				if err != nil || n != 1 {
+					t.Errorf("expected one user to keep the last name, got %d (%v)", n, err)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should fail if set changes nothing",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `mutation { updateUsers(where: {}, set: {}) { users { id } userErrors { field code message } } }`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetData(got).Path("$.updateUsers.userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("message").Equal("set must change at least one field")
+				errors.First().Object().Value("code").Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package mutation_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/idempotency"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestIdempotency(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropAll(t, client)
+		},
+	})
+	defer teardown()
+
+	createUser := func(key string, email string) *httpexpect.Response {
+		return expect.POST(router.QueryPath).
+			WithHeader(idempotency.Header, key).
+			WithJSON(map[string]interface{}{
+				"query": `
+					mutation CreateUser($input: CreateUserInput!) {
+						createUser(input: $input) {
+							user {
+								id
+							}
+							userErrors {
+								message
+							}
+						}
+					}`,
+				"variables": map[string]interface{}{
+					"input": map[string]interface{}{
+						"firstName": "John",
+						"lastName":  "Doe",
+						"email":     email,
+						"password":  "secret1234",
+					},
+				},
+			}).Expect()
+	}
+	// failFinish makes storing the response of a key fail, like a lost connection after the mutation
+	failFinish := false
+	client.IdempotencyKey.Use(func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			if failFinish && m.Op().Is(ent.OpUpdate) {
+				return nil, errors.New("connection lost")
+			}
+			return next.Mutate(ctx, m)
+		})
+	})
+	countUsers := func(t *testing.T) int {
+		n, err := client.User.Query().Count(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		arrange  func(t *testing.T)
+		act      func(t *testing.T) *httpexpect.Response
+		assert   func(t *testing.T, got *httpexpect.Response)
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should replay the response of a retried mutation",
+			arrange: func(t *testing.T) {
+				got := createUser("key-1", "john@yourname.xyz")
+				got.Status(http.StatusOK)
+				got.JSON().Object().NotContainsKey("errors")
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return createUser("key-1", "john@yourname.xyz")
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				payload := e2e.GetData(got).Path("$.createUser").Object()
+				payload.Value("userErrors").Array().Empty()
+				u, err := client.User.Query().Only(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				payload.Path("$.user.id").String().Equal(string(u.ID))
+				got.JSON().Object().Path("$.extensions.idempotentReplay").Boolean().True()
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropAll(t, client)
+			},
+		},
+		{
+			name: "it should roll back the mutation if its response could not be stored, so that a retry succeeds",
+			arrange: func(t *testing.T) {
+				failFinish = true
+				defer func() { failFinish = false }()
+				got := createUser("key-5", "john@yourname.xyz")
+				got.Status(http.StatusOK)
+				e2e.GetErrors(got).Array().Length().Equal(1)
+				if n := countUsers(t); n != 0 {
+					t.Errorf("expected the user to be rolled back, got %d users", n)
+				}
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return createUser("key-5", "john@yourname.xyz")
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.JSON().Object().NotContainsKey("errors")
+				payload := e2e.GetData(got).Path("$.createUser").Object()
+				payload.Value("userErrors").Array().Empty()
+				payload.Path("$.user.id").String().NotEmpty()
+				if n := countUsers(t); n != 1 {
+					t.Errorf("expected the user to be created, got %d users", n)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropAll(t, client)
+			},
+		},
+		{
+			name: "it should fail if the key is reused with a different payload",
+			arrange: func(t *testing.T) {
+				createUser("key-2", "john@yourname.xyz").Status(http.StatusOK)
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return createUser("key-2", "jack@yourname.xyz")
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetErrors(got).Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("message").Equal("idempotency key was used with a different operation")
+				errors.First().Object().Path("$.extensions.code").Equal("CONFLICT")
+				if n := countUsers(t); n != 1 {
+					t.Errorf("expected only the first user, got %d", n)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropAll(t, client)
+			},
+		},
+		{
+			name: "it should execute the mutation again once the key expired",
+			arrange: func(t *testing.T) {
+				_, err := client.IdempotencyKey.Create().
+					SetKey("key-3").
+					SetOperationHash("outdated").
+					SetResponse([]byte(`{"data":{}}`)).
+					SetExpiresAt(time.Now().Add(-time.Minute)).
+					Save(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return createUser("key-3", "john@yourname.xyz")
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				e2e.GetData(got).Path("$.createUser.user.id").String().NotEmpty()
+				if n := countUsers(t); n != 1 {
+					t.Errorf("expected the user to be created, got %d users", n)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropAll(t, client)
+			},
+		},
+		{
+			name:    "it should reject batches with a key",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).
+					WithHeader(idempotency.Header, "key-4").
+					WithJSON([]map[string]string{
+						{"query": `{ users { totalCount } }`},
+						{"query": `{ users { totalCount } }`},
+					}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusBadRequest)
+				errors := e2e.GetErrors(got).Array()
+				errors.First().Object().Value("message").Equal("idempotency key is not supported for batches")
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
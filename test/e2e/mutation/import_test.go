@@ -0,0 +1,172 @@
+package mutation_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestUser_ImportUsers(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	importUsers := func(file string, dryRun bool) *httpexpect.Response {
+		variables := `{"file": null, "dryRun": false}`
+		if dryRun {
+			variables = `{"file": null, "dryRun": true}`
+		}
+		return expect.POST(router.QueryPath).WithMultipart().
+			WithFormField("operations", `{
+				"query": "mutation ($file: Upload!, $dryRun: Boolean) { importUsers(file: $file, dryRun: $dryRun) { report { dryRun rows imported errors { row field message } } userErrors { field code message } } }",
+				"variables": `+variables+`
+			}`).
+			WithFormField("map", `{"0": ["variables.file"]}`).
+			WithFileBytes("0", "users.csv", []byte(file)).
+			Expect()
+	}
+	countUsers := func(t *testing.T) int {
+		n, err := client.User.Query().Count(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T)
+		act     func(t *testing.T) *httpexpect.Response
+		assert  func(t *testing.T, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name:    "it should create a user for every row",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return importUsers("firstName,lastName,email,password\n"+
+					"John,Doe,john@yourname.xyz,secret1234\n"+
+					"Jack,Sparrow,jack@yourname.xyz,secret1234\n", false)
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				report := e2e.GetData(got).Path("$.importUsers.report").Object()
+				report.ValueEqual("rows", 2)
+				report.ValueEqual("imported", 2)
+				report.Value("errors").Array().Empty()
+				if n := countUsers(t); n != 2 {
+					t.Errorf("expected 2 users, got %d", n)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should report the errors of every row and create no user",
+			arrange: func(t *testing.T) {
+				_, err := client.User.Create().
+					SetFirstName("Jane").
+					SetLastName("Doe").
+					SetEmail("jane@yourname.xyz").
+					SetPassword("secret1234").
+					Save(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return importUsers("email,firstName,lastName,password\n"+
+					"john@yourname.xyz,John,Doe,secret1234\n"+
+					"JOHN@yourname.xyz,Johnny,Doe,secret1234\n"+
+					"jane@yourname.xyz,Jane,Doe,secret1234\n"+
+					"invalid,Jack,Sparrow,jacksecret\n"+
+					"jim@yourname.xyz,Jim\n", false)
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				report := e2e.GetData(got).Path("$.importUsers.report").Object()
+				report.ValueEqual("rows", 5)
+				report.ValueEqual("imported", 0)
+				errors := report.Value("errors").Array()
+				errors.Length().Equal(5)
+				errors.Element(0).Object().ValueEqual("row", 3)
+				errors.Element(0).Object().ValueEqual("field", "email")
+				errors.Element(0).Object().ValueEqual("message", "email appears more than once in the file")
+				errors.Element(1).Object().ValueEqual("row", 4)
+				errors.Element(1).Object().ValueEqual("message", "user with the given email already exists")
+				errors.Element(2).Object().ValueEqual("row", 5)
+				errors.Element(2).Object().ValueEqual("field", "email")
+				errors.Element(3).Object().ValueEqual("row", 5)
+				errors.Element(3).Object().ValueEqual("field", "password")
+				errors.Element(4).Object().ValueEqual("row", 6)
+				errors.Element(4).Object().Value("field").Null()
+				if n := countUsers(t); n != 1 {
+					t.Errorf("expected only the existing user, got %d", n)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should not create users in a dry run",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return importUsers("firstName,lastName,email,password\n"+
+					"John,Doe,john@yourname.xyz,secret1234\n", true)
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				report := e2e.GetData(got).Path("$.importUsers.report").Object()
+				report.ValueEqual("dryRun", true)
+				report.ValueEqual("rows", 1)
+				report.ValueEqual("imported", 0)
+				report.Value("errors").Array().Empty()
+				if n := countUsers(t); n != 0 {
+					t.Errorf("expected no users, got %d", n)
+				}
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name:    "it should fail if a column is missing",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return importUsers("firstName,lastName,email\nJohn,Doe,john@yourname.xyz\n", false)
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				payload := e2e.GetData(got).Path("$.importUsers").Object()
+				payload.Value("report").Null()
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("message").Equal("csv file must have the columns firstName, lastName, email and password")
+				errors.First().Object().Value("code").Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
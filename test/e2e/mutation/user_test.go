@@ -40,12 +40,19 @@ func TestUser_CreateUser(t *testing.T) {
 							createUser(
 								input: {firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret12345"}
 							) {
-								id
-								firstName
-								lastName
-								email
-								createdAt
-								updatedAt
+								user {
+									id
+									firstName
+									lastName
+									email
+									createdAt
+									updatedAt
+								}
+								userErrors {
+									field
+									code
+									message
+								}
 							}
 						}`,
 				}).Expect()
@@ -53,7 +60,9 @@ func TestUser_CreateUser(t *testing.T) {
 			assert: func(_ *testing.T, got *httpexpect.Response) {
 				got.Status(http.StatusOK)
 				res := e2e.GetData(got).Object()
-				user := e2e.GetObject(res, "createUser")
+				payload := e2e.GetObject(res, "createUser")
+				payload.Value("userErrors").Array().Empty()
+				user := payload.Value("user").Object()
 				user.Value("id").String().NotEmpty()
 				user.Value("firstName").String().Equal("John")
 				user.Value("lastName").String().Equal("Doe")
@@ -65,6 +74,35 @@ func TestUser_CreateUser(t *testing.T) {
 				testutil.DropUser(t, client)
 			},
 		},
+		{
+			name:    "it should format timestamps in the requested timezone and format",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `
+						mutation CreateUser {
+							createUser(
+								input: {firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret12345"}
+							) {
+								user {
+									createdAt(timezone: "Asia/Tokyo", format: RFC3339)
+									updatedAt(format: DATE)
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				res := e2e.GetData(got).Object()
+				user := e2e.GetObject(res, "createUser").Value("user").Object()
+				user.Value("createdAt").String().Match(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\+09:00$`)
+				user.Value("updatedAt").String().Match(`^\d{4}-\d{2}-\d{2}$`)
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
 		{
 			name:    "it should fail if password is shorter than 8 characters",
 			arrange: func(t *testing.T) {},
@@ -75,24 +113,32 @@ func TestUser_CreateUser(t *testing.T) {
 							createUser(
 								input: {firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret"}
 							) {
-								id
-								firstName
-								lastName
-								email
-								createdAt
-								updatedAt
+								user {
+									id
+									firstName
+									lastName
+									email
+									createdAt
+									updatedAt
+								}
+								userErrors {
+									field
+									code
+									message
+								}
 							}
 						}`,
 				}).Expect()
 			},
 			assert: func(_ *testing.T, got *httpexpect.Response) {
 				got.Status(http.StatusOK)
-				res := e2e.GetData(got)
-				res.Null()
+				payload := e2e.GetData(got).Path("$.createUser").Object()
+				payload.Value("user").Null()
 
-				errors := e2e.GetErrors(got)
-				errors.Array().Length().Equal(1)
-				errors.Array().First().Object().Value("message").Equal("password must be at least 8 characters in length")
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("message").Equal("password must be at least 8 characters in length")
+				errors.First().Object().Value("code").Equal("VALIDATION")
 			},
 			teardown: func(t *testing.T) {
 				testutil.DropUser(t, client)
@@ -108,24 +154,143 @@ func TestUser_CreateUser(t *testing.T) {
 							createUser(
 								input: {firstName: "John", lastName: "Doe", email: "john@yourname", password: "secret12345"}
 							) {
-								id
-								firstName
-								lastName
-								email
-								createdAt
-								updatedAt
+								user {
+									id
+									firstName
+									lastName
+									email
+									createdAt
+									updatedAt
+								}
+								userErrors {
+									field
+									code
+									message
+								}
 							}
 						}`,
 				}).Expect()
 			},
 			assert: func(_ *testing.T, got *httpexpect.Response) {
 				got.Status(http.StatusOK)
-				res := e2e.GetData(got)
-				res.Null()
+				payload := e2e.GetData(got).Path("$.createUser").Object()
+				payload.Value("user").Null()
+
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("message").Equal("email must be a valid email address")
+				errors.First().Object().Value("code").Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should report every invalid field",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `
+						mutation CreateUser {
+							createUser(
+								input: {firstName: "John", lastName: "Doe", email: "john@yourname", password: "secret"}
+							) {
+								user {
+									id
+								}
+								userErrors {
+									field
+									code
+									message
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.JSON().Object().NotContainsKey("errors")
+				payload := e2e.GetData(got).Path("$.createUser").Object()
+				payload.Value("user").Null()
+
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(2)
 
-				errors := e2e.GetErrors(got)
-				errors.Array().Length().Equal(1)
-				errors.Array().First().Object().Value("message").Equal("email must be a valid email address")
+				email := errors.Element(0).Object()
+				email.Value("message").Equal("email must be a valid email address")
+				email.Value("field").Equal([]string{"input", "email"})
+				email.Value("code").Equal("VALIDATION")
+
+				password := errors.Element(1).Object()
+				password.Value("message").Equal("password must be at least 8 characters in length")
+				password.Value("field").Equal([]string{"input", "password"})
+				password.Value("code").Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should localise errors by Accept-Language",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithHeader(router.HeaderAcceptLanguage, "fr-CH, en;q=0.5").WithJSON(map[string]string{
+					"query": `
+						mutation CreateUser {
+							createUser(
+								input: {firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret"}
+							) {
+								user {
+									id
+								}
+								userErrors {
+									field
+									code
+									message
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetData(got).Path("$.createUser.userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("message").Equal("password doit faire une taille minimum de 8 caractères")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should fail if password contains the name of the user",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `
+						mutation CreateUser {
+							createUser(
+								input: {firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "johnny12345"}
+							) {
+								user {
+									id
+								}
+								userErrors {
+									field
+									code
+									message
+								}
+							}
+						}`,
+				}).Expect()
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetData(got).Path("$.createUser.userErrors").Array()
+				errors.Length().Equal(1)
+				err := errors.First().Object()
+				err.Value("message").Equal("password must not contain the first or last name")
+				err.Value("field").Equal([]string{"input", "password"})
 			},
 			teardown: func(t *testing.T) {
 				testutil.DropUser(t, client)
@@ -148,24 +313,214 @@ func TestUser_CreateUser(t *testing.T) {
 							createUser(
 								input: {firstName: "John", lastName: "Doe", email: "john@yourname.xyz", password: "secret1234"}
 							) {
-								id
-								firstName
-								lastName
-								email
-								createdAt
-								updatedAt
+								user {
+									id
+									firstName
+									lastName
+									email
+									createdAt
+									updatedAt
+								}
+								userErrors {
+									field
+									code
+									message
+								}
 							}
 						}`,
 				}).Expect()
 			},
 			assert: func(_ *testing.T, got *httpexpect.Response) {
 				got.Status(http.StatusOK)
-				res := e2e.GetData(got)
-				res.Null()
+				payload := e2e.GetData(got).Path("$.createUser").Object()
+				payload.Value("user").Null()
 
-				errors := e2e.GetErrors(got)
-				errors.Array().Length().Equal(1)
-				errors.Array().First().Object().Value("message").Equal("user with the given email already exists")
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("message").Equal("user with the given email already exists")
+				errors.First().Object().Value("code").Equal("CONFLICT")
+				errors.First().Object().Value("field").Null()
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
+
+func TestUser_UpdateUser(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	var id string
+	arrange := func(t *testing.T) {
+		u, err := client.User.Create().
+			SetFirstName("John").
+			SetLastName("Doe").
+			SetEmail("john@yourname.xyz").
+			SetPassword("secret1234").
+			Save(context.Background())
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		id = string(u.ID)
+	}
+	updateUser := func(input map[string]interface{}) *httpexpect.Response {
+		input["id"] = id
+		return expect.POST(router.QueryPath).WithJSON(map[string]interface{}{
+			"query": `
+				mutation UpdateUser($input: UpdateUserInput!) {
+					updateUser(input: $input) {
+						user {
+							firstName
+							lastName
+							email
+							version
+						}
+						userErrors {
+							field
+							code
+							message
+						}
+					}
+				}`,
+			"variables": map[string]interface{}{"input": input},
+		}).Expect()
+	}
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T)
+		act     func(t *testing.T) *httpexpect.Response
+		assert  func(t *testing.T, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name:    "it should only change the given fields",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return updateUser(map[string]interface{}{"lastName": "Smith"})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				payload := e2e.GetData(got).Path("$.updateUser").Object()
+				payload.Value("userErrors").Array().Empty()
+				user := payload.Value("user").Object()
+				user.Value("firstName").String().Equal("John")
+				user.Value("lastName").String().Equal("Smith")
+				user.Value("email").String().Equal("john@yourname.xyz")
+				user.Value("version").Number().Equal(2)
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should fail if a field which can not be cleared is null",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return updateUser(map[string]interface{}{"firstName": nil, "lastName": "Smith"})
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				payload := e2e.GetData(got).Path("$.updateUser").Object()
+				payload.Value("user").Null()
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("field").Equal([]string{"input", "firstName"})
+				errors.First().Object().Value("message").Equal("firstName must not be null")
+
+				u, err := client.User.Query().Only(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if u.FirstName != "John" || u.LastName != "Doe" {
+					t.Errorf("expected the user to be unchanged, got %s %s", u.FirstName, u.LastName)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should update the user if the expected version matches",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return updateUser(map[string]interface{}{"lastName": "Smith", "expectedVersion": 1})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				payload := e2e.GetData(got).Path("$.updateUser").Object()
+				payload.Value("userErrors").Array().Empty()
+				payload.Value("user").Object().Value("version").Number().Equal(2)
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should fail if the user was changed since the expected version",
+			arrange: func(t *testing.T) {
+				arrange(t)
+				_, err := client.User.Update().SetLastName("Sparrow").Save(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return updateUser(map[string]interface{}{"lastName": "Smith", "expectedVersion": 1})
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				payload := e2e.GetData(got).Path("$.updateUser").Object()
+				payload.Value("user").Null()
+				errors := payload.Value("userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("code").Equal("CONFLICT")
+				errors.First().Object().Value("message").Equal("user was changed since the expected version")
+
+				u, err := client.User.Query().Only(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if u.LastName != "Sparrow" || u.Version != 2 {
+					t.Errorf("expected the concurrent change to be kept, got %s version %d", u.LastName, u.Version)
+				}
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should only validate the given fields",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return updateUser(map[string]interface{}{"email": "john@yourname"})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetData(got).Path("$.updateUser.userErrors").Array()
+				errors.Length().Equal(1)
+				errors.First().Object().Value("field").Equal([]string{"input", "email"})
+				errors.First().Object().Value("message").Equal("email must be a valid email address")
 			},
 			teardown: func(t *testing.T) {
 				testutil.DropUser(t, client)
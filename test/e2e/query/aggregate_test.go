@@ -0,0 +1,160 @@
+package query_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestUser_UsersAggregate(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	arrange := func(t *testing.T) {
+		ctx := context.Background()
+		for _, u := range []struct {
+			first, email string
+			createdAt    time.Time
+		}{
+			{"John", "john@yourname.xyz", time.Date(2021, 1, 10, 12, 0, 0, 0, time.UTC)},
+			{"Jack", "jack@yourname.xyz", time.Date(2021, 1, 20, 12, 0, 0, 0, time.UTC)},
+			{"Jane", "jane@example.com", time.Date(2021, 2, 5, 12, 0, 0, 0, time.UTC)},
+		} {
+			_, err := client.User.Create().
+				SetFirstName(u.first).
+				SetLastName("Doe").
+				SetEmail(u.email).
+				SetPassword("secret1234").
+				SetCreatedAt(u.createdAt).
+				Save(ctx)
+			if err != nil {
+				t.Error(err)
+				t.FailNow()
+			}
+		}
+	}
+	aggregate := func(query string) *httpexpect.Response {
+		return expect.POST(router.QueryPath).WithJSON(map[string]string{
+			"query": query,
+		}).Expect()
+	}
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T)
+		act     func(t *testing.T) *httpexpect.Response
+		assert  func(t *testing.T, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name:    "it should count all users without groups",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return aggregate(`{ usersAggregate { firstName bucket count } }`)
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				groups := e2e.GetData(got).Path("$.usersAggregate").Array()
+				groups.Length().Equal(1)
+				groups.First().Object().Value("count").Equal(3)
+				groups.First().Object().Value("firstName").Null()
+				groups.First().Object().Value("bucket").Null()
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should count users per month and email domain",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return aggregate(`{
+					usersAggregate(groupBy: [EMAIL_DOMAIN], interval: MONTH) {
+						bucket(format: DATE)
+						emailDomain
+						count
+					}
+				}`)
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				groups := e2e.GetData(got).Path("$.usersAggregate").Array()
+				groups.Length().Equal(2)
+				groups.Element(0).Object().ValueEqual("bucket", "2021-01-01")
+				groups.Element(0).Object().ValueEqual("emailDomain", "yourname.xyz")
+				groups.Element(0).Object().ValueEqual("count", 2)
+				groups.Element(1).Object().ValueEqual("bucket", "2021-02-01")
+				groups.Element(1).Object().ValueEqual("emailDomain", "example.com")
+				groups.Element(1).Object().ValueEqual("count", 1)
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should group by repeated properties once",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return aggregate(`{ usersAggregate(groupBy: [EMAIL_DOMAIN, EMAIL_DOMAIN]) { emailDomain count } }`)
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.JSON().Object().NotContainsKey("errors")
+				groups := e2e.GetData(got).Path("$.usersAggregate").Array()
+				groups.Length().Equal(2)
+				groups.Element(0).Object().ValueEqual("emailDomain", "example.com")
+				groups.Element(0).Object().ValueEqual("count", 1)
+				groups.Element(1).Object().ValueEqual("emailDomain", "yourname.xyz")
+				groups.Element(1).Object().ValueEqual("count", 2)
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should only count users matching the filter",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return aggregate(`{
+					usersAggregate(where: { firstNameHasPrefix: "Ja" }, groupBy: [FIRST_NAME]) {
+						firstName
+						count
+					}
+				}`)
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				groups := e2e.GetData(got).Path("$.usersAggregate").Array()
+				groups.Length().Equal(2)
+				groups.Element(0).Object().ValueEqual("firstName", "Jack")
+				groups.Element(1).Object().ValueEqual("firstName", "Jane")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package query_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestCache_CacheControl(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		arrange  func(t *testing.T)
+		act      func(t *testing.T) *httpexpect.Response
+		assert   func(t *testing.T, got *httpexpect.Response)
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should allow shared caching of public fields",
+			arrange: func(t *testing.T) {
+				_, err := client.User.Create().
+					SetFirstName("John").
+					SetLastName("Doe").
+					SetEmail("john@yourname.xyz").
+					SetPassword("secret1234").
+					Save(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `{ users { edges { node { id firstName } } } }`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.Header("Cache-Control").Equal("max-age=30, public")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should only allow private caching of private fields",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `{ users { edges { node { id email } } } }`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.Header("Cache-Control").Equal("max-age=30, private")
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name:    "it should not allow caching of mutations",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `mutation { createUser(input: {firstName: "Jack", lastName: "Sparrow", email: "jack@yourname.xyz", password: "secret1234"}) { user { id } } }`,
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.Header("Cache-Control").Equal("no-store")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should serve repeated anonymous GET queries from the cache",
+			arrange: func(t *testing.T) {
+				got := expect.GET(router.QueryPath).
+					WithQuery("query", `{ users { edges { node { id firstName } } } }`).
+					Expect()
+				got.Status(http.StatusOK)
+				got.Header(router.HeaderXCache).Equal("MISS")
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.QueryPath).
+					WithQuery("query", `{ users { edges { node { id firstName } } } }`).
+					Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.Header(router.HeaderXCache).Equal("HIT")
+				got.Header("Cache-Control").Equal("max-age=30, public")
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name: "it should invalidate cached responses containing users after a mutation of a user",
+			arrange: func(t *testing.T) {
+				got := expect.GET(router.QueryPath).
+					WithQuery("query", `{ users { totalCount } }`).
+					Expect()
+				got.Status(http.StatusOK)
+				got.Header(router.HeaderXCache).Equal("MISS")
+				e2e.GetData(got).Path("$.users.totalCount").Number().Equal(0)
+
+				expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `mutation { createUser(input: {firstName: "Jack", lastName: "Sparrow", email: "jack@yourname.xyz", password: "secret1234"}) { user { id } } }`,
+				}).Expect().Status(http.StatusOK)
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.QueryPath).
+					WithQuery("query", `{ users { totalCount } }`).
+					Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.Header(router.HeaderXCache).Equal("MISS")
+				e2e.GetData(got).Path("$.users.totalCount").Number().Equal(1)
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
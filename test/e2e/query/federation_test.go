@@ -0,0 +1,123 @@
+package query_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/schema/ulid"
+	"gitlab.com/trustify/core/pkg/const/globalid"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestFederation_Entities(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T) []string
+		act     func(t *testing.T, ids []string) *httpexpect.Response
+		assert  func(t *testing.T, ids []string, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should resolve user references in the order of the representations",
+			arrange: func(t *testing.T) []string {
+				ctx := context.Background()
+				john, err := client.User.Create().
+					SetFirstName("John").
+					SetLastName("Doe").
+					SetEmail("john@yourname.xyz").
+					SetPassword("secret1234").
+					Save(ctx)
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				jack, err := client.User.Create().
+					SetFirstName("Jack").
+					SetLastName("Sparrow").
+					SetEmail("jack@yourname.xyz").
+					SetPassword("secret1234").
+					Save(ctx)
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				missing := ulid.MustNew(globalid.New().User.Prefix)
+
+				return []string{string(jack.ID), string(missing), string(john.ID)}
+			},
+			act: func(t *testing.T, ids []string) *httpexpect.Response {
+				representations := make([]map[string]interface{}, len(ids))
+				for i, id := range ids {
+					representations[i] = map[string]interface{}{"__typename": "User", "id": id}
+				}
+				return expect.POST(router.QueryPath).WithJSON(map[string]interface{}{
+					"query": `
+						query Entities($representations: [_Any!]!) {
+							_entities(representations: $representations) {
+								... on User {
+									id
+									firstName
+								}
+							}
+						}`,
+					"variables": map[string]interface{}{"representations": representations},
+				}).Expect()
+			},
+			assert: func(_ *testing.T, ids []string, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				entities := e2e.GetData(got).Path("$._entities").Array()
+				entities.Length().Equal(3)
+				entities.Element(0).Object().Value("id").Equal(ids[0])
+				entities.Element(0).Object().Value("firstName").Equal("Jack")
+				entities.Element(1).Null()
+				entities.Element(2).Object().Value("id").Equal(ids[2])
+				entities.Element(2).Object().Value("firstName").Equal("John")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should expose the schema of the subgraph",
+			arrange: func(t *testing.T) []string {
+				return nil
+			},
+			act: func(t *testing.T, _ []string) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": `{ _service { sdl } }`,
+				}).Expect()
+			},
+			assert: func(_ *testing.T, _ []string, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				sdl := e2e.GetData(got).Path("$._service.sdl").String()
+				sdl.Contains(`@link(url: "https://specs.apollo.dev/federation/v2.0"`)
+				sdl.Contains(`@key(fields: "id")`)
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := tt.arrange(t)
+			got := tt.act(t, ids)
+			tt.assert(t, ids, got)
+			tt.teardown(t)
+		})
+	}
+}
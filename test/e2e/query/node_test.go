@@ -0,0 +1,182 @@
+package query_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/ent/schema/ulid"
+	"gitlab.com/trustify/core/pkg/const/globalid"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestNode_Nodes(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T) []string
+		act     func(t *testing.T, ids []string) *httpexpect.Response
+		assert  func(t *testing.T, ids []string, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should return nodes in the order of the ids and null for missing ids",
+			arrange: func(t *testing.T) []string {
+				ctx := context.Background()
+				john, err := client.User.Create().
+					SetFirstName("John").
+					SetLastName("Doe").
+					SetEmail("john@yourname.xyz").
+					SetPassword("secret1234").
+					Save(ctx)
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				jack, err := client.User.Create().
+					SetFirstName("Jack").
+					SetLastName("Sparrow").
+					SetEmail("jack@yourname.xyz").
+					SetPassword("secret1234").
+					Save(ctx)
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				missing := ulid.MustNew(globalid.New().User.Prefix)
+
+				return []string{string(jack.ID), string(missing), string(john.ID)}
+			},
+			act: func(t *testing.T, ids []string) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]interface{}{
+					"query": `
+						query Nodes($ids: [ID!]!) {
+							nodes(ids: $ids) {
+								id
+								... on User {
+									firstName
+								}
+							}
+						}`,
+					"variables": map[string]interface{}{"ids": ids},
+				}).Expect()
+			},
+			assert: func(_ *testing.T, ids []string, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.JSON().Object().NotContainsKey("errors")
+				nodes := e2e.GetData(got).Path("$.nodes").Array()
+				nodes.Length().Equal(3)
+				nodes.Element(0).Object().Value("id").Equal(ids[0])
+				nodes.Element(0).Object().Value("firstName").Equal("Jack")
+				nodes.Element(1).Null()
+				nodes.Element(2).Object().Value("id").Equal(ids[2])
+				nodes.Element(2).Object().Value("firstName").Equal("John")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should return null for a single missing id",
+			arrange: func(t *testing.T) []string {
+				return []string{string(ulid.MustNew(globalid.New().User.Prefix))}
+			},
+			act: func(t *testing.T, ids []string) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]interface{}{
+					"query":     `query Nodes($ids: [ID!]!) { nodes(ids: $ids) { id } }`,
+					"variables": map[string]interface{}{"ids": ids},
+				}).Expect()
+			},
+			assert: func(_ *testing.T, _ []string, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.JSON().Object().NotContainsKey("errors")
+				nodes := e2e.GetData(got).Path("$.nodes").Array()
+				nodes.Length().Equal(1)
+				nodes.Element(0).Null()
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name: "it should return null for an id with an unknown prefix",
+			arrange: func(t *testing.T) []string {
+				john, err := client.User.Create().
+					SetFirstName("John").
+					SetLastName("Doe").
+					SetEmail("john@yourname.xyz").
+					SetPassword("secret1234").
+					Save(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+
+				return []string{"xxx_01G0000000000000000000000", string(john.ID)}
+			},
+			act: func(t *testing.T, ids []string) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]interface{}{
+					"query":     `query Nodes($ids: [ID!]!) { nodes(ids: $ids) { id } }`,
+					"variables": map[string]interface{}{"ids": ids},
+				}).Expect()
+			},
+			assert: func(_ *testing.T, ids []string, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				got.JSON().Object().NotContainsKey("errors")
+				nodes := e2e.GetData(got).Path("$.nodes").Array()
+				nodes.Length().Equal(2)
+				nodes.Element(0).Null()
+				nodes.Element(1).Object().Value("id").Equal(ids[1])
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name: "it should fail if too many ids are requested",
+			arrange: func(t *testing.T) []string {
+				ids := make([]string, config.C.GraphQL.MaxNodes+1)
+				for i := range ids {
+					ids[i] = string(ulid.MustNew(globalid.New().User.Prefix))
+				}
+				return ids
+			},
+			act: func(t *testing.T, ids []string) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON(map[string]string{
+					"query": fmt.Sprintf(`{ nodes(ids: ["%s"]) { id } }`, strings.Join(ids, `", "`)),
+				}).Expect()
+			},
+			assert: func(_ *testing.T, _ []string, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetErrors(got)
+				errors.Array().Length().Equal(1)
+				errors.Array().First().Object().Value("message").Equal("too many ids requested")
+				errors.Array().First().Object().Path("$.extensions.code").Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := tt.arrange(t)
+			got := tt.act(t, ids)
+			tt.assert(t, ids, got)
+			tt.teardown(t)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package query_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+const searchUsersQuery = `
+	query SearchUsers($query: String!, $first: Int, $after: Cursor) {
+		searchUsers(query: $query, first: $first, after: $after) {
+			totalCount
+			fuzzy
+			pageInfo {
+				hasNextPage
+				endCursor
+			}
+			edges {
+				rank
+				highlight
+				node {
+					firstName
+				}
+			}
+		}
+	}`
+
+func TestUser_SearchUsers(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	arrange := func(t *testing.T) {
+		ctx := context.Background()
+		for _, u := range []struct{ first, last, email string }{
+			{"John", "Doe", "john@yourname.xyz"},
+			{"Johnny", "Smith", "johnny@yourname.xyz"},
+			{"Jack", "Sparrow", "jack@yourname.xyz"},
+		} {
+			_, err := client.User.Create().
+				SetFirstName(u.first).
+				SetLastName(u.last).
+				SetEmail(u.email).
+				SetPassword("secret1234").
+				Save(ctx)
+			if err != nil {
+				t.Error(err)
+				t.FailNow()
+			}
+		}
+	}
+	search := func(variables map[string]interface{}) *httpexpect.Response {
+		return expect.POST(router.QueryPath).WithJSON(map[string]interface{}{
+			"query":     searchUsersQuery,
+			"variables": variables,
+		}).Expect()
+	}
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T)
+		act     func(t *testing.T) *httpexpect.Response
+		assert  func(t *testing.T, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name:    "it should find users by prefixes of their words and highlight the matches",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return search(map[string]interface{}{"query": "joh smi"})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				conn := e2e.GetData(got).Path("$.searchUsers").Object()
+				conn.Value("totalCount").Equal(1)
+				conn.Value("fuzzy").Equal(false)
+				edges := conn.Value("edges").Array()
+				edges.Length().Equal(1)
+				edges.First().Path("$.node.firstName").Equal("Johnny")
+				edges.First().Object().Value("highlight").String().Contains("<mark>Johnny</mark> <mark>Smith</mark>")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should paginate the users ordered by rank",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				got := search(map[string]interface{}{"query": "john", "first": 1})
+				got.Status(http.StatusOK)
+				conn := e2e.GetData(got).Path("$.searchUsers").Object()
+				conn.Value("totalCount").Equal(2)
+				conn.Path("$.pageInfo.hasNextPage").Equal(true)
+				conn.Value("edges").Array().Length().Equal(1)
+				cursor := conn.Path("$.pageInfo.endCursor").String().Raw()
+
+				return search(map[string]interface{}{"query": "john", "first": 1, "after": cursor})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				conn := e2e.GetData(got).Path("$.searchUsers").Object()
+				conn.Path("$.pageInfo.hasNextPage").Equal(false)
+				conn.Value("edges").Array().Length().Equal(1)
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should fall back to similar words if nothing matches",
+			arrange: arrange,
+			act: func(t *testing.T) *httpexpect.Response {
+				return search(map[string]interface{}{"query": "sparow"})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				conn := e2e.GetData(got).Path("$.searchUsers").Object()
+				conn.Value("fuzzy").Equal(true)
+				edges := conn.Value("edges").Array()
+				edges.Length().Equal(1)
+				edges.First().Path("$.node.firstName").Equal("Jack")
+				edges.First().Object().Value("highlight").Null()
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should fail if first is negative",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return search(map[string]interface{}{"query": "john", "first": -1})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetErrors(got)
+				errors.Array().Length().Equal(1)
+				errors.Array().First().Object().Value("message").Equal("first must be between 0 and the maximum page size")
+				errors.Array().First().Object().Path("$.extensions.code").Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name:    "it should fail if first exceeds the maximum page size of searches",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return search(map[string]interface{}{"query": "john", "first": config.C.Search.MaxPageSize + 1})
+			},
+			assert: func(_ *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				errors := e2e.GetErrors(got)
+				errors.Array().Length().Equal(1)
+				errors.Array().First().Object().Value("message").Equal("first must be between 0 and the maximum page size")
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package query_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/config"
+	"gitlab.com/trustify/core/ent"
+	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/testutil"
+	"gitlab.com/trustify/core/testutil/e2e"
+)
+
+func TestTransport(t *testing.T) {
+	expect, client, teardown := e2e.Setup(t, e2e.SetupOption{
+		Teardown: func(t *testing.T, client *ent.Client) {
+			testutil.DropUser(t, client)
+		},
+	})
+	defer teardown()
+
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T)
+		act     func(t *testing.T) *httpexpect.Response
+		assert  func(t *testing.T, got *httpexpect.Response)
+		args    struct {
+			ctx context.Context
+		}
+		teardown func(t *testing.T)
+	}{
+		{
+			name: "it should execute queries sent with GET",
+			arrange: func(t *testing.T) {
+				_, err := client.User.Create().
+					SetFirstName("John").
+					SetLastName("Doe").
+					SetEmail("john@yourname.xyz").
+					SetPassword("secret1234").
+					Save(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+			},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.QueryPath).
+					WithQuery("query", `{ users { edges { node { firstName } } } }`).
+					Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				data := got.JSON().Object().Value("data").Object()
+				data.Path("$.users.edges[0].node.firstName").String().Equal("John")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should reject mutations sent with GET",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.GET(router.QueryPath).
+					WithQuery("query", `mutation { createUser(input: {firstName: "Jack", lastName: "Sparrow", email: "jack@yourname.xyz", password: "secret1234"}) { user { id } } }`).
+					Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusNotAcceptable)
+				count, err := client.User.Query().Count(context.Background())
+				if err != nil {
+					t.Error(err)
+					t.FailNow()
+				}
+				if count != 0 {
+					t.Errorf("got %d users, want 0", count)
+				}
+			},
+			teardown: func(t *testing.T) {},
+		},
+		{
+			name:    "it should execute batched operations in order",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				return expect.POST(router.QueryPath).WithJSON([]map[string]string{
+					{"query": `mutation { createUser(input: {firstName: "Jack", lastName: "Sparrow", email: "jack@yourname.xyz", password: "secret1234"}) { user { firstName } } }`},
+					{"query": `{ users { totalCount } }`},
+					{"query": `{ users {`},
+				}).Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusOK)
+				results := got.JSON().Array()
+				results.Length().Equal(3)
+				results.Element(0).Path("$.data.createUser.user.firstName").String().Equal("Jack")
+				results.Element(1).Path("$.data.users.totalCount").Number().Equal(1)
+				results.Element(2).Path("$.errors[0].extensions.code").String().Equal("GRAPHQL_PARSE_FAILED")
+			},
+			teardown: func(t *testing.T) {
+				testutil.DropUser(t, client)
+			},
+		},
+		{
+			name:    "it should reject batches exceeding the limit",
+			arrange: func(t *testing.T) {},
+			act: func(t *testing.T) *httpexpect.Response {
+				ops := make([]string, config.C.GraphQL.MaxBatchSize+1)
+				for i := range ops {
+					ops[i] = `{"query": "{ __typename }"}`
+				}
+				return expect.POST(router.QueryPath).
+					WithHeader("Content-Type", "application/json").
+					WithText(fmt.Sprintf("[%s]", strings.Join(ops, ","))).
+					Expect()
+			},
+			assert: func(t *testing.T, got *httpexpect.Response) {
+				got.Status(http.StatusBadRequest)
+				got.JSON().Path("$.errors[0].extensions.code").String().Equal("VALIDATION")
+			},
+			teardown: func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arrange(t)
+			got := tt.act(t)
+			tt.assert(t, got)
+			tt.teardown(t)
+		})
+	}
+}
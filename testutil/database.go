@@ -2,24 +2,49 @@ package testutil
 
 import (
 	"context"
+	"database/sql"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"entgo.io/ent/dialect"
 	"gitlab.com/trustify/core/ent"
-	"gitlab.com/trustify/core/ent/enttest"
 	"gitlab.com/trustify/core/pkg/infrastructure/datastore"
 )
 
-// NewDBClient loads database for test
+// NewDBClient applies the migrations to the database for test and returns a client of it.
+// The schema is created by the committed migrations like in production instead of the automatic migration of ent.
 func NewDBClient(t *testing.T) *ent.Client {
+	t.Helper()
 	d := datastore.New()
-	return enttest.Open(t, dialect.Postgres, d)
+
+	db, err := sql.Open(dialect.Postgres, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := datastore.NewMigrator(db, MigrationDir()).Up(context.Background(), 0); err != nil {
+		t.Fatalf("failed applying migrations: %v", err)
+	}
+
+	client, err := ent.Open(dialect.Postgres, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// MigrationDir returns the absolute path of the committed migrations
+func MigrationDir() string {
+	_, b, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(b), "..", datastore.MigrationDir)
 }
 
 // DropAll drops all data from database
 func DropAll(t *testing.T, client *ent.Client) {
 	t.Log("drop data from database")
 	DropUser(t, client)
+	DropIdempotencyKey(t, client)
 }
 
 // DropUser drops data from users
@@ -31,3 +56,13 @@ func DropUser(t *testing.T, client *ent.Client) {
 		t.FailNow()
 	}
 }
+
+// DropIdempotencyKey drops data from idempotency_keys
+func DropIdempotencyKey(t *testing.T, client *ent.Client) {
+	ctx := context.Background()
+	_, err := client.IdempotencyKey.Delete().Exec(ctx)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"testing"
 
 	"github.com/gavv/httpexpect/v2"
+	"gitlab.com/trustify/core/config"
 	"gitlab.com/trustify/core/ent"
 	"gitlab.com/trustify/core/pkg/adapter/controller"
+	"gitlab.com/trustify/core/pkg/infrastructure/cache"
 	"gitlab.com/trustify/core/pkg/infrastructure/graphql"
 	"gitlab.com/trustify/core/pkg/infrastructure/router"
+	"gitlab.com/trustify/core/pkg/infrastructure/storage"
 	"gitlab.com/trustify/core/pkg/registry"
+	"gitlab.com/trustify/core/pkg/usercase/repository"
 	"gitlab.com/trustify/core/testutil"
 )
 
@@ -24,9 +28,16 @@ func Setup(t *testing.T, option SetupOption) (expect *httpexpect.Expect, client
 	testutil.ReadConfigE2E()
 
 	client = testutil.NewDBClient(t)
-	ctrl := newController(client)
+	// files are served by the test server, so their URLs are relative
+	files, err := storage.NewLocal(t.TempDir(), storage.LocalPath, config.C.Storage.Local.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctrl := newController(client, files)
 	gqlsrv := graphql.NewServer(client, ctrl)
-	e := router.New(gqlsrv)
+	responseCache := cache.NewMemory(0)
+	client.Use(cache.InvalidationHook(responseCache))
+	e := router.New(gqlsrv, responseCache, files, ctrl)
 
 	srv := httptest.NewServer(e)
 
@@ -43,8 +54,8 @@ func Setup(t *testing.T, option SetupOption) (expect *httpexpect.Expect, client
 		}
 }
 
-func newController(client *ent.Client) controller.Controller {
-	r := registry.New(client)
+func newController(client *ent.Client, fileStorage repository.Storage) controller.Controller {
+	r := registry.New(client, fileStorage)
 	return r.NewController()
 }
 